@@ -0,0 +1,218 @@
+package http
+
+import (
+	"errors"
+	"github.com/dobyte/http/internal/xconv"
+	"reflect"
+	"strings"
+)
+
+const (
+	tagPath   = "path"
+	tagQuery  = "query"
+	tagHeader = "header"
+	tagCookie = "cookie"
+	tagForm   = "form"
+	tagJson   = "json"
+)
+
+// binding is a request struct split by the tags of its fields.
+type binding struct {
+	path        map[string]string
+	query       map[string]interface{}
+	headers     map[string]string
+	cookies     map[string]string
+	body        map[string]interface{}
+	contentType string
+	untagged    []untaggedField
+}
+
+type untaggedField struct {
+	name  string
+	value interface{}
+}
+
+// bind split a request struct whose fields are tagged with path, query, header, cookie, form or json.
+// It returns nil if data is not a struct or none of its fields carries a path, query, header, cookie or form tag,
+// in which case data is sent the way it always was. The exported fields without these tags are named by their
+// field names, as encoding/json does, they are body fields when the struct has form or json fields or the method
+// has a body, otherwise they are query params, the way the data of a GET request always was.
+//
+//	type GetRepo struct {
+//		Org    string `path:"org"`
+//		Page   int    `query:"page,omitempty"`
+//		Tenant string `header:"X-Tenant"`
+//		Sid    string `cookie:"sid"`
+//		Name   string `json:"name"`
+//	}
+func bind(data interface{}, method string) (*binding, error) {
+	rv := reflect.ValueOf(data)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct || !isBindable(rv.Type()) {
+		return nil, nil
+	}
+
+	b := &binding{
+		path:    make(map[string]string),
+		query:   make(map[string]interface{}),
+		headers: make(map[string]string),
+		cookies: make(map[string]string),
+	}
+
+	if err := b.bindStruct(rv); err != nil {
+		return nil, err
+	}
+
+	for _, field := range b.untagged {
+		if b.body == nil && !hasBody(method) {
+			if _, ok := b.query[field.name]; !ok {
+				b.query[field.name] = field.value
+			}
+			continue
+		}
+
+		if b.body == nil {
+			b.body, b.contentType = make(map[string]interface{}), ContentTypeJson
+		}
+
+		if _, ok := b.body[field.name]; !ok {
+			b.body[field.name] = field.value
+		}
+	}
+
+	return b, nil
+}
+
+// hasBody determine if the requests of the method usually have a body.
+func hasBody(method string) bool {
+	switch method {
+	case MethodGet, MethodHead, MethodDelete, MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// isBindable determine if any field of the struct carries a binding tag.
+func isBindable(rt reflect.Type) bool {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		for _, tag := range []string{tagPath, tagQuery, tagHeader, tagCookie, tagForm} {
+			if _, ok := field.Tag.Lookup(tag); ok {
+				return true
+			}
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if field.Anonymous && ft.Kind() == reflect.Struct && isBindable(ft) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b *binding) bindStruct(rv reflect.Value) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		var (
+			field = rt.Field(i)
+			fv    = rv.Field(i)
+		)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Anonymous && field.Tag == "" {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct {
+				if err := b.bindStruct(fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		tagged := false
+
+		for _, tag := range []string{tagPath, tagQuery, tagHeader, tagCookie, tagForm, tagJson} {
+			value, ok := field.Tag.Lookup(tag)
+			if !ok {
+				continue
+			}
+
+			tagged = true
+
+			name, omitEmpty := parseBindingTag(value, field.Name)
+			if name == "-" || omitEmpty && fv.IsZero() {
+				break
+			}
+
+			switch tag {
+			case tagPath:
+				b.path[name] = xconv.String(fv.Interface())
+			case tagQuery:
+				b.query[name] = fv.Interface()
+			case tagHeader:
+				b.headers[name] = xconv.String(fv.Interface())
+			case tagCookie:
+				b.cookies[name] = xconv.String(fv.Interface())
+			case tagForm, tagJson:
+				contentType := ContentTypeJson
+				if tag == tagForm {
+					contentType = ContentTypeFormUrlEncoded
+				}
+
+				if b.contentType != "" && b.contentType != contentType {
+					return errors.New("http: request struct can't mix form and json fields")
+				}
+
+				if b.body == nil {
+					b.body = make(map[string]interface{})
+				}
+
+				b.body[name], b.contentType = fv.Interface(), contentType
+			}
+
+			break
+		}
+
+		if !tagged {
+			b.untagged = append(b.untagged, untaggedField{name: field.Name, value: fv.Interface()})
+		}
+	}
+
+	return nil
+}
+
+// parseBindingTag returns the name and the omitempty option of a tag.
+func parseBindingTag(tag, fieldName string) (name string, omitEmpty bool) {
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	if name = parts[0]; name == "" {
+		name = fieldName
+	}
+
+	return
+}
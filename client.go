@@ -11,25 +11,44 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/base64"
+	"fmt"
+	"github.com/dobyte/http/internal/codec"
+	"github.com/dobyte/http/internal/query"
+	"io"
 	"net/http"
-	"net/http/cookiejar"
+	"net/url"
+	"reflect"
 	"sync"
 	"time"
 )
 
 type Client struct {
 	http.Client
-	ctx           context.Context
-	baseUrl       string
-	retryCount    int
-	retryInterval time.Duration
+	parent         *Client
+	ctx            context.Context
+	baseUrl        string
+	base           *url.URL
+	retryCount     int
+	retryInterval  time.Duration
+	statusError    bool
+	forbidExternal bool
+	errorType      reflect.Type
+	errorValue     interface{}
+	debug          io.Writer
 
 	rw          sync.RWMutex
-	headers     map[string]string
-	cookies     map[string]string
+	headers     http.Header
+	headerOrder *headerOrder
+	cookies     *cookieStore
+	codecs      *codec.Registry
 	middlewares []MiddlewareFunc
+
+	queryEncoder *query.Encoder
 }
 
+// Codec encodes request bodies and multipart fields, and decodes response bodies of a media type.
+type Codec = codec.Codec
+
 const (
 	defaultUserAgent = "DobyteHttpClient"
 
@@ -55,9 +74,11 @@ func NewClient() *Client {
 				},
 			},
 		},
-		headers:     make(map[string]string),
-		cookies:     make(map[string]string),
-		middlewares: make([]MiddlewareFunc, 0),
+		headers:      make(http.Header),
+		cookies:      newCookieStore(),
+		codecs:       codec.NewRegistry(),
+		middlewares:  make([]MiddlewareFunc, 0),
+		queryEncoder: &query.Encoder{},
 	}
 
 	c.SetHeader(HeaderUserAgent, defaultUserAgent)
@@ -65,12 +86,12 @@ func NewClient() *Client {
 	return c
 }
 
-// SetHeader Set a common header for the client.
+// SetHeader Set a common header for the client, replacing the values of the key.
 func (c *Client) SetHeader(key, value string) {
 	c.rw.Lock()
 	defer c.rw.Unlock()
 
-	c.headers[key] = value
+	c.headers.Set(key, value)
 }
 
 // SetHeaders Set multiple common headers for the client.
@@ -79,54 +100,139 @@ func (c *Client) SetHeaders(headers map[string]string) {
 	defer c.rw.Unlock()
 
 	for key, value := range headers {
-		c.headers[key] = value
+		c.headers.Set(key, value)
 	}
 }
 
-// GetHeaders Returns all common headers.
+// AddHeader Add a value to a common header of the client, eg: a repeated Accept or X-Forwarded-For.
+func (c *Client) AddHeader(key, value string) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	c.headers.Add(key, value)
+}
+
+// DelHeader Delete a common header of the client.
+func (c *Client) DelHeader(key string) {
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	c.headers.Del(key)
+}
+
+// GetHeader Returns the first value of a common header.
+func (c *Client) GetHeader(key string) string {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return c.headers.Get(key)
+}
+
+// GetHeaderValues Returns all values of a common header.
+func (c *Client) GetHeaderValues(key string) []string {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+
+	return append([]string(nil), c.headers.Values(key)...)
+}
+
+// GetHeaders Returns the first value of all common headers by their canonical keys.
 func (c *Client) GetHeaders() map[string]string {
 	c.rw.RLock()
 	defer c.rw.RUnlock()
 
 	headers := make(map[string]string, len(c.headers))
-	for key, value := range c.headers {
-		headers[key] = value
+	for key, values := range c.headers {
+		if len(values) > 0 {
+			headers[key] = values[0]
+		}
 	}
 
 	return headers
 }
 
-// SetCookie Set a common cookie for the client.
-func (c *Client) SetCookie(key, value string) {
-	c.rw.Lock()
-	defer c.rw.Unlock()
+// cloneHeaders returns a copy of the common headers.
+func (c *Client) cloneHeaders() http.Header {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
 
-	c.cookies[key] = value
+	return c.headers.Clone()
 }
 
-// SetCookies Set multiple common cookies for the client.
-func (c *Client) SetCookies(cookies map[string]string) {
-	c.rw.Lock()
-	defer c.rw.Unlock()
+// SetCookie Set a common cookie for the client, which is sent to every host and path.
+func (c *Client) SetCookie(key, value string) {
+	c.cookies.set(&http.Cookie{Name: key, Value: value})
+}
 
+// SetCookies Set multiple common cookies for the client, which are sent to every host and path.
+func (c *Client) SetCookies(cookies map[string]string) {
 	for key, value := range cookies {
-		c.cookies[key] = value
+		c.cookies.set(&http.Cookie{Name: key, Value: value})
 	}
 }
 
-// GetCookies Returns all common cookies.
+// GetCookies Returns the names and values of all common cookies which are not expired.
 func (c *Client) GetCookies() map[string]string {
-	c.rw.RLock()
-	defer c.rw.RUnlock()
+	all := c.cookies.all()
 
-	cookies := make(map[string]string, len(c.cookies))
-	for key, value := range c.cookies {
-		cookies[key] = value
+	cookies := make(map[string]string, len(all))
+	for _, cookie := range all {
+		cookies[cookie.Name] = cookie.Value
 	}
 
 	return cookies
 }
 
+// AddCookies Add common cookies for the client, scoped by their Domain, Path, Secure, Expires and MaxAge.
+// A cookie replaces the one with the same name, domain and path, and an expired cookie deletes it.
+// The domain matches its subdomains, and an empty domain or path matches every host or path.
+func (c *Client) AddCookies(cookies ...*http.Cookie) {
+	c.cookies.set(cookies...)
+}
+
+// GetCookiesFor Returns the common cookies sent to the url, ordered as they are in the Cookie header.
+func (c *Client) GetCookiesFor(rawUrl string) []*http.Cookie {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil
+	}
+
+	return c.cookies.match(u)
+}
+
+// DelCookie Delete the common cookies with the name in all domains and paths.
+func (c *Client) DelCookie(name string) {
+	c.cookies.del(name)
+}
+
+// ClearCookies Delete all common cookies.
+func (c *Client) ClearCookies() {
+	c.cookies.clear()
+}
+
+// RegisterCodec Register codecs for the client by their content types, replacing the builtin ones.
+// Media types with a +json or +xml suffix fall back to the json and xml codecs.
+func (c *Client) RegisterCodec(codecs ...Codec) {
+	c.codecs.Register(codecs...)
+}
+
+// GetCodec Returns the codec of the content type.
+func (c *Client) GetCodec(contentType string) (Codec, bool) {
+	return c.codecs.Lookup(contentType)
+}
+
+// SetQueryEncoder Set the encoder of query strings and url encoded forms for the client, nil restores the default one.
+// The encoder replaces the form codec, including one registered by RegisterCodec before,
+// register a form codec afterwards to encode the url encoded bodies differently from the queries.
+func (c *Client) SetQueryEncoder(encoder *QueryEncoder) {
+	if encoder == nil {
+		encoder = &QueryEncoder{}
+	}
+
+	c.queryEncoder = encoder
+	c.codecs.Register(codec.NewFormCodec(encoder))
+}
+
 // SetUserAgent Set User-Agent for the request.
 func (c *Client) SetUserAgent(agent string) {
 	c.SetHeader(HeaderUserAgent, agent)
@@ -137,14 +243,50 @@ func (c *Client) SetContentType(contentType string) {
 	c.SetHeader(HeaderContentType, contentType)
 }
 
-// SetBrowserMode Enable browser mode for the request.
+// SetBrowserMode Enable browser mode for the request, the cookies of responses are kept in memory.
 func (c *Client) SetBrowserMode() {
-	c.Jar, _ = cookiejar.New(nil)
+	c.Jar, _ = NewCookieJar()
 }
 
-// SetBaseUrl Set base url for the client.
-func (c *Client) SetBaseUrl(baseUrl string) {
-	c.baseUrl = baseUrl
+// SetCookieJar Set the jar which keeps the cookies of responses for the client, eg: a CookieJar saved to disk.
+func (c *Client) SetCookieJar(jar http.CookieJar) {
+	c.Jar = jar
+}
+
+// SetBaseUrl Set base url for the client, an empty string removes it.
+// The base url must be an absolute http, https, ws or wss url, and its path is a prefix of relative request urls,
+// eg: with https://example.com/api/v2, both "users" and "/users" resolve to https://example.com/api/v2/users.
+func (c *Client) SetBaseUrl(baseUrl string) error {
+	if baseUrl == "" {
+		c.baseUrl, c.base = "", nil
+		return nil
+	}
+
+	u, err := url.Parse(baseUrl)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("http: base url %q must be an absolute http, https, ws or wss url", baseUrl)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("http: base url %q has no host", baseUrl)
+	}
+
+	u.Fragment, u.RawFragment = "", ""
+	c.baseUrl, c.base = baseUrl, u
+
+	return nil
+}
+
+// SetForbidExternalUrl Set whether urls resolved to a scheme or a host other than the ones of the base url,
+// or carrying userinfo, are rejected, which guards against SSRF when request urls come from untrusted input.
+func (c *Client) SetForbidExternalUrl(forbid bool) {
+	c.forbidExternal = forbid
 }
 
 // GetBaseUrl Returns base url of the client.
@@ -177,6 +319,36 @@ func (c *Client) SetRetry(retryCount int, retryInterval time.Duration) {
 	c.retryCount, c.retryInterval = retryCount, retryInterval
 }
 
+// SetStatusError Set whether a non-2xx response is returned as a *StatusError for the client.
+func (c *Client) SetStatusError(enable bool) {
+	c.statusError = enable
+}
+
+// SetError Register an error struct for the client, which is decoded from the body of each non-2xx response.
+// A pointer, eg: &apiErr, is reset and filled on each failure, so it's not safe for concurrent requests;
+// a struct value, eg: apiErr, is used as a type and a new one is decoded for each failure.
+// The sessions derived from the client use a registered pointer as a type as well.
+// The decoded value is available as StatusError.Value, and registering it enables status errors.
+func (c *Client) SetError(v interface{}) {
+	c.errorType, c.errorValue = nil, nil
+
+	if v == nil {
+		return
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		c.errorValue = v
+		return
+	}
+
+	rt := reflect.TypeOf(v)
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	c.errorType = rt
+}
+
 func (c *Client) SetKeepAlive(enable bool) {
 	//c.Transport.
 }
@@ -190,12 +362,15 @@ func (c *Client) Use(middlewares ...MiddlewareFunc) {
 }
 
 func (c *Client) getMiddlewares() []MiddlewareFunc {
+	var middlewares []MiddlewareFunc
+	if c.parent != nil {
+		middlewares = c.parent.getMiddlewares()
+	}
+
 	c.rw.RLock()
 	defer c.rw.RUnlock()
 
-	middlewares := make([]MiddlewareFunc, len(c.middlewares))
-	copy(middlewares, c.middlewares)
-	return middlewares
+	return append(middlewares, c.middlewares...)
 }
 
 // Download a file from the remote address to the local.
@@ -208,7 +383,25 @@ func (c *Client) Upload(url string, files interface{}, data interface{}, opts ..
 	return newUpload(c).request(url, files, data, opts...)
 }
 
+// ParseCurl Parse a curl command line, eg: one copied from the devtools of browsers, into a request of the client.
+// It supports -X, -H, -d, --data-raw, --data-binary, --data-urlencode, --json, -F, --form-string, -u, -b, -A, -e,
+// -G, -I and --compressed, the url is taken literally as with --globoff. The options of the transport are left
+// to the client, so -k is only accepted when its transport skips the verification of certificates, as the one of
+// NewClient does, and it's rejected rather than ignored otherwise. Local files are only read in CurlOptions.FileDir.
+// The url resolves against the base url, and the headers and cookies of the command replace the common ones
+// of the client, the opts replace them in turn.
+func (c *Client) ParseCurl(command string, opts ...*CurlOptions) (*http.Request, error) {
+	return newCurl(c).prepare(command, opts...)
+}
+
+// Curl Send the request of a curl command line through the middlewares of the client, see ParseCurl.
+func (c *Client) Curl(command string, opts ...*CurlOptions) (*Response, error) {
+	return newCurl(c).request(command, opts...)
+}
+
 // Request send an http request.
+// The data may be a request struct whose fields are tagged with path, query, header, cookie, form or json,
+// each field is sent as the url path param, query param, header, cookie, form field or json member of the request.
 func (c *Client) Request(method, url string, data interface{}, opts ...*RequestOptions) (*Response, error) {
 	return newRequest(c).request(method, url, data, opts...)
 }
@@ -0,0 +1,95 @@
+// Command dobyte-http-gen generates typed clients on top of github.com/dobyte/http.
+//
+// It implements annotated interfaces, usually invoked by go generate:
+//
+//	//go:generate go run github.com/dobyte/http/cmd/dobyte-http-gen -type UserAPI
+//
+// See the documentation of gen.GenerateInterfaces for the annotations.
+//
+// It also generates the models and a client from an OpenAPI 3.0/3.1 document in JSON or YAML:
+//
+//	//go:generate go run github.com/dobyte/http/cmd/dobyte-http-gen -openapi petstore.yaml -output petstore_http.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/dobyte/http/internal/gen"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma-separated list of interface names to implement")
+		source    = flag.String("source", os.Getenv("GOFILE"), "the go file declaring the interfaces, defaults to $GOFILE")
+		output    = flag.String("output", "", "output file name, defaults to <source>_http.go")
+		spec      = flag.String("openapi", "", "the OpenAPI 3 document to generate a client from")
+		pkg       = flag.String("package", os.Getenv("GOPACKAGE"), "the package name of the generated client, defaults to $GOPACKAGE")
+	)
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: dobyte-http-gen -type Name[,Name] [-source file.go] [-output file.go]\n")
+		fmt.Fprintf(os.Stderr, "       dobyte-http-gen -openapi spec.yaml [-package name] [-output file.go]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var err error
+
+	switch {
+	case *spec != "":
+		if *pkg == "" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		err = runOpenAPI(*spec, *pkg, *output)
+	case *typeNames != "" && *source != "":
+		err = run(strings.Split(*typeNames, ","), *source, *output)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(names []string, source, output string) error {
+	src, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+
+	out, err := gen.GenerateInterfaces(source, src, names)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(source, filepath.Ext(source)) + "_http.go"
+	}
+
+	return os.WriteFile(output, out, 0644)
+}
+
+func runOpenAPI(spec, pkg, output string) error {
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		return err
+	}
+
+	out, err := gen.GenerateOpenAPI(data, pkg)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(filepath.Base(spec), filepath.Ext(spec)) + "_http.go"
+	}
+
+	return os.WriteFile(output, out, 0644)
+}
@@ -0,0 +1,239 @@
+package http
+
+import (
+	"github.com/dobyte/http/internal/cookiejar"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CookieFormatAuto     = cookiejar.FormatAuto
+	CookieFormatJSON     = cookiejar.FormatJSON
+	CookieFormatNetscape = cookiejar.FormatNetscape
+)
+
+// CookieJar is a http.CookieJar which can be saved to and loaded from disk in JSON or Netscape cookies.txt format.
+type CookieJar = cookiejar.Jar
+
+type CookieJarOptions = cookiejar.Options
+
+// CookieEntry is a cookie stored in a CookieJar.
+type CookieEntry = cookiejar.Entry
+
+type CookieFormat = cookiejar.Format
+
+// NewCookieJar Create a cookie jar, which loads CookieJarOptions.Filename if it exists.
+// Cookies for a public suffix like co.uk are rejected by the builtin public suffix list.
+// Close the jar to stop the autosave and save it.
+func NewCookieJar(opts ...*CookieJarOptions) (*CookieJar, error) {
+	if len(opts) > 0 {
+		return cookiejar.New(opts[0])
+	}
+
+	return cookiejar.New(nil)
+}
+
+// cookieStore keeps the common cookies of a client, scoped by their domain and path.
+// A cookie without domain is sent to every host, a cookie without path is sent to every path.
+type cookieStore struct {
+	mu      sync.RWMutex
+	seq     uint64
+	entries map[string]*cookieEntry
+}
+
+type cookieEntry struct {
+	cookie  *http.Cookie
+	expires time.Time
+	seq     uint64
+}
+
+func newCookieStore() *cookieStore {
+	return &cookieStore{entries: make(map[string]*cookieEntry)}
+}
+
+// set add or replace cookies, a cookie with the same name, domain and path is replaced.
+// A cookie which is already expired or has a negative MaxAge deletes the stored one.
+func (s *cookieStore) set(cookies ...*http.Cookie) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	for _, cookie := range cookies {
+		if cookie == nil || cookie.Name == "" {
+			continue
+		}
+
+		c := *cookie
+		c.Domain = strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+		if c.Path == "" || c.Path[0] != '/' {
+			c.Path = "/"
+		}
+
+		key := c.Domain + ";" + c.Path + ";" + c.Name
+
+		var expires time.Time
+		switch {
+		case c.MaxAge < 0:
+			delete(s.entries, key)
+			continue
+		case c.MaxAge > 0:
+			expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+			c.Expires, c.MaxAge = expires, 0
+		case !c.Expires.IsZero():
+			if !c.Expires.After(now) {
+				delete(s.entries, key)
+				continue
+			}
+			expires = c.Expires
+		}
+
+		seq := s.seq
+		if e, ok := s.entries[key]; ok {
+			seq = e.seq
+		} else {
+			s.seq++
+		}
+
+		s.entries[key] = &cookieEntry{cookie: &c, expires: expires, seq: seq}
+	}
+}
+
+// del delete the cookies with the name in all domains and paths.
+func (s *cookieStore) del(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, e := range s.entries {
+		if e.cookie.Name == name {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// clear delete all cookies.
+func (s *cookieStore) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]*cookieEntry)
+}
+
+// all returns the cookies which are not expired, in the order they were set.
+func (s *cookieStore) all() []*http.Cookie {
+	return s.filter(func(*http.Cookie) bool { return true })
+}
+
+// match returns the cookies to send to the url, ordered by RFC 6265:
+// cookies with longer paths first, then the ones set earlier.
+func (s *cookieStore) match(u *url.URL) []*http.Cookie {
+	var (
+		host   = strings.ToLower(u.Hostname())
+		path   = u.EscapedPath()
+		secure = u.Scheme == "https" || u.Scheme == "wss"
+	)
+
+	if path == "" {
+		path = "/"
+	}
+
+	cookies := s.filter(func(c *http.Cookie) bool {
+		return (!c.Secure || secure) && domainMatch(host, c.Domain) && pathMatch(path, c.Path)
+	})
+
+	sort.SliceStable(cookies, func(i, j int) bool {
+		return len(cookies[i].Path) > len(cookies[j].Path)
+	})
+
+	return cookies
+}
+
+func (s *cookieStore) filter(fn func(c *http.Cookie) bool) []*http.Cookie {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		now     = time.Now()
+		entries = make([]*cookieEntry, 0, len(s.entries))
+	)
+
+	for key, e := range s.entries {
+		if !e.expires.IsZero() && !e.expires.After(now) {
+			delete(s.entries, key)
+			continue
+		}
+
+		if fn(e.cookie) {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+
+	cookies := make([]*http.Cookie, len(entries))
+	for i, e := range entries {
+		c := *e.cookie
+		cookies[i] = &c
+	}
+
+	return cookies
+}
+
+// domainMatch determine if the host matches the domain of a cookie by RFC 6265 section 5.1.3,
+// an empty domain matches every host.
+func domainMatch(host, domain string) bool {
+	if domain == "" || host == domain {
+		return true
+	}
+
+	return strings.HasSuffix(host, "."+domain) && !isIP(host)
+}
+
+// pathMatch determine if the request path matches the path of a cookie by RFC 6265 section 5.1.4.
+func pathMatch(path, cookiePath string) bool {
+	if path == cookiePath {
+		return true
+	}
+
+	if !strings.HasPrefix(path, cookiePath) {
+		return false
+	}
+
+	return strings.HasSuffix(cookiePath, "/") || path[len(cookiePath)] == '/'
+}
+
+func isIP(host string) bool {
+	return strings.Trim(host, "0123456789.") == "" || strings.Contains(host, ":")
+}
+
+// cookieHeader format the Cookie header of a request by RFC 6265 section 5.4.
+// The cookies in overrides replace the stored cookies with the same name.
+func cookieHeader(cookies []*http.Cookie, overrides map[string]string) string {
+	pairs := make([]string, 0, len(cookies)+len(overrides))
+
+	for _, c := range cookies {
+		if _, ok := overrides[c.Name]; !ok {
+			pairs = append(pairs, (&http.Cookie{Name: c.Name, Value: c.Value}).String())
+		}
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if pair := (&http.Cookie{Name: name, Value: overrides[name]}).String(); pair != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return strings.Join(pairs, "; ")
+}
@@ -0,0 +1,818 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// curlCommand is a curl command line parsed by parseCurl.
+type curlCommand struct {
+	method     string
+	url        string
+	header     http.Header
+	cookies    map[string]string
+	data       []string
+	forms      []curlForm
+	get        bool
+	head       bool
+	compressed bool
+	insecure   bool
+	files      curlFiles
+}
+
+// curlForm is a -F or --form-string argument.
+type curlForm struct {
+	name        string
+	value       string
+	path        string
+	filename    string
+	contentType string
+	file        bool
+}
+
+type CurlOptions struct {
+	RequestOptions
+	// FileDir is the directory of the files read by @file, <file and name@file arguments, a relative path is
+	// relative to it and a path out of it is rejected. No file is read by default, as a pasted command
+	// could send any local file to the host it names.
+	FileDir string
+}
+
+type curl struct {
+	req *request
+}
+
+func newCurl(client *Client) *curl {
+	return &curl{req: newRequest(client)}
+}
+
+// send the request of a curl command line.
+func (r *curl) request(command string, opts ...*CurlOptions) (*Response, error) {
+	req, err := r.prepare(command, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.req.call(req)
+}
+
+// skipsVerify determine if the transport skips the verification of certificates, as curl -k does.
+func skipsVerify(transport http.RoundTripper) bool {
+	switch t := transport.(type) {
+	case *http.Transport:
+		return t.TLSClientConfig != nil && t.TLSClientConfig.InsecureSkipVerify
+	case *HandlerTransport:
+		return true
+	default:
+		return false
+	}
+}
+
+// build the request of a curl command line.
+func (r *curl) prepare(command string, opts ...*CurlOptions) (req *http.Request, err error) {
+	var (
+		options = &RequestOptions{}
+		dir     string
+	)
+
+	if len(opts) > 0 && opts[0] != nil {
+		*options, dir = opts[0].RequestOptions, opts[0].FileDir
+	}
+
+	cmd, err := parseCurl(command, curlFiles{dir: dir})
+	if err != nil {
+		return
+	}
+
+	// the transport of the client decides the verification, which must not silently differ from the command
+	if cmd.insecure && !skipsVerify(r.req.client.Transport) {
+		return nil, errors.New("http: curl option --insecure requires the transport of the client to skip the verification of certificates")
+	}
+
+	body, contentType, files, err := cmd.body()
+	if err != nil {
+		return
+	}
+
+	rawUrl := cmd.url
+	if cmd.get && len(body) > 0 {
+		if strings.Contains(rawUrl, "?") {
+			rawUrl += "&" + string(body)
+		} else {
+			rawUrl += "?" + string(body)
+		}
+		body, contentType = nil, ""
+	}
+
+	header := cmd.header.Clone()
+	if contentType != "" && (len(cmd.forms) > 0 || header.Get(HeaderContentType) == "") {
+		header.Set(HeaderContentType, contentType)
+	}
+
+	for key, value := range options.Headers {
+		header.Set(key, value)
+	}
+
+	for key, values := range options.Header {
+		header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	cookies := make(map[string]string, len(cmd.cookies)+len(options.Cookies))
+	for name, value := range cmd.cookies {
+		cookies[name] = value
+	}
+
+	for name, value := range options.Cookies {
+		cookies[name] = value
+	}
+
+	options.Headers, options.Header, options.Cookies = nil, header, cookies
+
+	if files != nil {
+		ctx := options.Context
+		if ctx == nil {
+			ctx = r.req.client.ctx
+		}
+
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		options.Context = context.WithValue(ctx, uploadFilesKey, files)
+	}
+
+	if req, err = r.req.prepare(cmd.method, rawUrl, nil, options); err != nil {
+		return
+	}
+
+	if cmd.compressed {
+		req.Header.Del("Accept-Encoding")
+	}
+
+	if len(body) > 0 {
+		req.ContentLength = int64(len(body))
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	return
+}
+
+// curlOptions are the options of curl taking a value, by their short and long names.
+var curlOptions = map[string]string{
+	"-X": "--request",
+	"-H": "--header",
+	"-d": "--data",
+	"-F": "--form",
+	"-u": "--user",
+	"-b": "--cookie",
+	"-A": "--user-agent",
+	"-e": "--referer",
+	"-o": "--output",
+	"-w": "--write-out",
+	"-m": "--max-time",
+}
+
+// curlFlags are the options of curl without value, by their short and long names.
+var curlFlags = map[string]string{
+	"-G": "--get",
+	"-I": "--head",
+	"-k": "--insecure",
+	"-g": "--globoff",
+	"-L": "--location",
+	"-s": "--silent",
+	"-S": "--show-error",
+	"-v": "--verbose",
+	"-i": "--include",
+	"-f": "--fail",
+}
+
+// parseCurl parse a curl command line, the urls are always taken literally as curl does with --globoff.
+// The files of the command are read through files.
+func parseCurl(command string, files curlFiles) (*curlCommand, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 || filepath.Base(args[0]) != "curl" {
+		return nil, errors.New("http: the command is not a curl command")
+	}
+
+	cmd := &curlCommand{header: make(http.Header), cookies: make(map[string]string), files: files}
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if cmd.url != "" {
+				return nil, fmt.Errorf("http: curl command has more than one url: %q", arg)
+			}
+			cmd.url = arg
+			continue
+		}
+
+		var name, value string
+		var hasValue bool
+
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			name = arg
+		default:
+			// short options may be combined, eg: -sSL or -XPOST
+			for j := 1; j < len(arg); j++ {
+				short := "-" + arg[j:j+1]
+				if long, ok := curlFlags[short]; ok {
+					if err = cmd.flag(long); err != nil {
+						return nil, err
+					}
+					continue
+				}
+
+				long, ok := curlOptions[short]
+				if !ok {
+					return nil, fmt.Errorf("http: unsupported curl option %q", short)
+				}
+
+				name = long
+				if j+1 < len(arg) {
+					value, hasValue = arg[j+1:], true
+				}
+				break
+			}
+
+			if name == "" {
+				continue
+			}
+		}
+
+		if isFlag(name) {
+			if err = cmd.flag(name); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("http: curl option %q needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		if err = cmd.option(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if cmd.url == "" {
+		return nil, errors.New("http: curl command has no url")
+	}
+
+	if !strings.Contains(cmd.url, "://") && !strings.HasPrefix(cmd.url, "/") {
+		cmd.url = "http://" + cmd.url
+	}
+
+	if cmd.method == "" {
+		switch {
+		case cmd.head:
+			cmd.method = MethodHead
+		case cmd.get:
+			cmd.method = MethodGet
+		case len(cmd.data) > 0 || len(cmd.forms) > 0:
+			cmd.method = MethodPost
+		default:
+			cmd.method = MethodGet
+		}
+	}
+
+	return cmd, nil
+}
+
+func isFlag(name string) bool {
+	switch name {
+	case "--compressed", "--http1.0", "--http1.1", "--http2", "--http2-prior-knowledge", "--no-buffer":
+		return true
+	}
+
+	for _, long := range curlFlags {
+		if long == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *curlCommand) flag(name string) error {
+	switch name {
+	case "--get":
+		c.get = true
+	case "--head":
+		c.head = true
+	case "--compressed":
+		c.compressed = true
+	case "--insecure":
+		c.insecure = true
+	}
+
+	return nil
+}
+
+func (c *curlCommand) option(name, value string) error {
+	switch name {
+	case "--request":
+		c.method = strings.ToUpper(value)
+	case "--url":
+		c.url = value
+	case "--header":
+		key, val, ok := strings.Cut(value, ":")
+		if !ok {
+			// "Name;" sends an empty header in curl
+			if key, ok = strings.CutSuffix(value, ";"); !ok {
+				return fmt.Errorf("http: invalid curl header %q", value)
+			}
+		}
+
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if strings.EqualFold(key, HeaderCookie) {
+			return c.option("--cookie", val)
+		}
+
+		c.header.Add(key, val)
+	case "--user-agent":
+		c.header.Set(HeaderUserAgent, value)
+	case "--referer":
+		c.header.Set("Referer", value)
+	case "--user":
+		c.header.Set(HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(value)))
+	case "--cookie":
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("http: curl cookie files are not supported: %q", value)
+		}
+
+		for _, pair := range strings.Split(value, ";") {
+			if name, val, ok := strings.Cut(strings.TrimSpace(pair), "="); ok && name != "" {
+				c.cookies[name] = val
+			}
+		}
+	case "--data", "--data-ascii":
+		data, err := readCurlData(value, true, c.files)
+		if err != nil {
+			return err
+		}
+		c.data = append(c.data, data)
+	case "--data-binary":
+		data, err := readCurlData(value, false, c.files)
+		if err != nil {
+			return err
+		}
+		c.data = append(c.data, data)
+	case "--data-raw":
+		c.data = append(c.data, value)
+	case "--data-urlencode":
+		data, err := encodeCurlData(value, c.files)
+		if err != nil {
+			return err
+		}
+		c.data = append(c.data, data)
+	case "--json":
+		data, err := readCurlData(value, false, c.files)
+		if err != nil {
+			return err
+		}
+		c.data = append(c.data, data)
+		if c.header.Get(HeaderContentType) == "" {
+			c.header.Set(HeaderContentType, ContentTypeJson)
+		}
+		if c.header.Get("Accept") == "" {
+			c.header.Set("Accept", ContentTypeJson)
+		}
+	case "--form":
+		form, err := parseCurlForm(value, c.files)
+		if err != nil {
+			return err
+		}
+		c.forms = append(c.forms, form)
+	case "--form-string":
+		name, val, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("http: invalid curl form %q", value)
+		}
+		c.forms = append(c.forms, curlForm{name: name, value: val})
+	case "--output", "--write-out", "--max-time", "--connect-timeout", "--retry":
+		// ignore
+	default:
+		return fmt.Errorf("http: unsupported curl option %q", name)
+	}
+
+	return nil
+}
+
+// body returns the body of the command and its content type, with the paths of the uploaded files.
+func (c *curlCommand) body() ([]byte, string, fileset, error) {
+	if len(c.forms) > 0 {
+		if len(c.data) > 0 {
+			return nil, "", nil, errors.New("http: curl command can't have both data and forms")
+		}
+
+		var (
+			buffer = &bytes.Buffer{}
+			writer = multipart.NewWriter(buffer)
+			files  = make(fileset)
+		)
+
+		for _, form := range c.forms {
+			if err := form.write(writer, files); err != nil {
+				return nil, "", nil, err
+			}
+		}
+
+		_ = writer.Close()
+
+		return buffer.Bytes(), writer.FormDataContentType(), files, nil
+	}
+
+	if len(c.data) == 0 {
+		return nil, "", nil, nil
+	}
+
+	return []byte(strings.Join(c.data, "&")), ContentTypeFormUrlEncoded, nil, nil
+}
+
+func (f curlForm) write(writer *multipart.Writer, files fileset) error {
+	header := make(textproto.MIMEHeader)
+
+	if !f.file {
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(f.name)))
+		if f.contentType != "" {
+			header.Set(HeaderContentType, f.contentType)
+		}
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(part, f.value)
+		return err
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	filename, contentType := f.filename, f.contentType
+	if filename == "" {
+		filename = filepath.Base(f.path)
+	}
+
+	if contentType == "" {
+		if contentType = mime.TypeByExtension(filepath.Ext(f.path)); contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	}
+
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(f.name), quoteEscaper.Replace(filename)))
+	header.Set(HeaderContentType, contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(part, file); err != nil {
+		return err
+	}
+
+	files.add(f.name, f.path)
+
+	return nil
+}
+
+// curlFiles resolves the files read by a curl command in a directory, no file is read without directory.
+type curlFiles struct {
+	dir string
+}
+
+// path returns the real path of the file, which must be in the directory after following the symbolic links.
+func (f curlFiles) path(name string) (string, error) {
+	if f.dir == "" {
+		return "", fmt.Errorf("http: curl command reads the local file %q, set CurlOptions.FileDir to allow it", name)
+	}
+
+	dir, err := filepath.Abs(f.dir)
+	if err == nil {
+		dir, err = filepath.EvalSymlinks(dir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return "", err
+	}
+
+	if rel, err := filepath.Rel(dir, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("http: curl command reads the file %q out of %q", name, f.dir)
+	}
+
+	return path, nil
+}
+
+func (f curlFiles) read(name string) ([]byte, error) {
+	path, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(path)
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// parseCurlForm parse a -F argument, eg: name=value, name=@path;type=text/plain;filename=a.txt or name=<path.
+func parseCurlForm(s string, files curlFiles) (curlForm, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return curlForm{}, fmt.Errorf("http: invalid curl form %q", s)
+	}
+
+	form := curlForm{name: name}
+
+	if strings.HasPrefix(value, "@") || strings.HasPrefix(value, "<") {
+		form.file = value[0] == '@'
+		value = value[1:]
+	}
+
+	parts := splitFormValue(value)
+	form.value = parts[0]
+
+	for _, param := range parts[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		switch strings.TrimSpace(key) {
+		case "type":
+			form.contentType = val
+		case "filename":
+			form.filename = val
+		}
+	}
+
+	if strings.HasPrefix(s[len(name)+1:], "<") {
+		buf, err := files.read(form.value)
+		if err != nil {
+			return curlForm{}, err
+		}
+		form.value = string(buf)
+	} else if form.file {
+		path, err := files.path(form.value)
+		if err != nil {
+			return curlForm{}, err
+		}
+		form.path, form.value = path, ""
+	}
+
+	return form, nil
+}
+
+// splitFormValue split a form value by ';', the segments starting with a double quote are unquoted
+// and may contain ';'.
+func splitFormValue(s string) []string {
+	var parts []string
+
+	for {
+		var sb strings.Builder
+
+		if strings.HasPrefix(s, `"`) {
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				sb.WriteByte(s[i])
+			}
+			s = s[min(i+1, len(s)):]
+		}
+
+		i := strings.IndexByte(s, ';')
+		if i < 0 {
+			return append(parts, sb.String()+s)
+		}
+
+		parts, s = append(parts, sb.String()+s[:i]), s[i+1:]
+	}
+}
+
+// readCurlData returns the data of a -d or --data-binary argument, which reads the file of @path,
+// -d strips the carriage returns and newlines of the file as curl does.
+func readCurlData(value string, strip bool, files curlFiles) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+
+	buf, err := files.read(value[1:])
+	if err != nil {
+		return "", err
+	}
+
+	if strip {
+		buf = bytes.ReplaceAll(bytes.ReplaceAll(buf, []byte("\r"), nil), []byte("\n"), nil)
+	}
+
+	return string(buf), nil
+}
+
+// encodeCurlData returns the data of a --data-urlencode argument,
+// which is content, =content, name=content, @path or name@path.
+func encodeCurlData(value string, files curlFiles) (string, error) {
+	var name, content string
+
+	if i := strings.IndexAny(value, "=@"); i < 0 {
+		content = value
+	} else if value[i] == '=' {
+		name, content = value[:i], value[i+1:]
+	} else {
+		buf, err := files.read(value[i+1:])
+		if err != nil {
+			return "", err
+		}
+		name, content = value[:i], string(buf)
+	}
+
+	if name == "" {
+		return url.QueryEscape(content), nil
+	}
+
+	return name + "=" + url.QueryEscape(content), nil
+}
+
+// splitCommand split a command line into words as a POSIX shell does, with the $'...' quoting of bash,
+// the backslash-newline continuations are removed.
+func splitCommand(s string) ([]string, error) {
+	var (
+		args   []string
+		sb     strings.Builder
+		inWord bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				args = append(args, sb.String())
+				sb.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			if i+1 < len(s) {
+				i++
+				if s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+					i++
+				}
+				if s[i] != '\n' && s[i] != '\r' {
+					sb.WriteByte(s[i])
+					inWord = true
+				}
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("http: unterminated single quote in command")
+			}
+			sb.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0 {
+					i++
+					if s[i] == '\n' {
+						continue
+					}
+				}
+				sb.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, errors.New("http: unterminated double quote in command")
+			}
+			inWord = true
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'':
+			n, err := unquoteAnsiC(s[i+2:], &sb)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 2
+			inWord = true
+		default:
+			sb.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		args = append(args, sb.String())
+	}
+
+	return args, nil
+}
+
+// unquoteAnsiC write the unquoted content of $'...' to sb, and returns the length consumed including the closing quote.
+func unquoteAnsiC(s string, sb *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if c == '\'' {
+			return i + 1, nil
+		}
+
+		if c != '\\' || i+1 >= len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch c = s[i]; c {
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'e', 'E':
+			sb.WriteByte(0x1b)
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			j := i + 1
+			for j < len(s) && j-i-1 < size && isHex(s[j]) {
+				j++
+			}
+			if j == i+1 {
+				sb.WriteByte('\\')
+				sb.WriteByte(c)
+				continue
+			}
+
+			n, _ := strconv.ParseUint(s[i+1:j], 16, 32)
+			if c == 'x' {
+				sb.WriteByte(byte(n))
+			} else {
+				sb.WriteRune(rune(n))
+			}
+			i = j - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j-i < 3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+
+			n, _ := strconv.ParseUint(s[i:j], 8, 16)
+			sb.WriteByte(byte(n))
+			i = j - 1
+		case '\\', '\'', '"', '?':
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		}
+	}
+
+	return 0, errors.New("http: unterminated $' quote in command")
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -0,0 +1,353 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultDumpBodySize = 4096
+
+// Dump Returns the request and the response as they were on the wire, after middlewares, headers, cookies and body.
+// The bodies are truncated to maxBodySize, 4096 bytes by default. Only the dumped part of an unread response body
+// is read into memory, the body is still read entirely by ReadBody or ScanBody afterwards.
+func (r *Response) Dump(maxBodySize ...int) string {
+	size := defaultDumpBodySize
+	if len(maxBodySize) > 0 && maxBodySize[0] > 0 {
+		size = maxBodySize[0]
+	}
+
+	return r.dump(size, false)
+}
+
+// dump returns the request and the response, whose sensitive headers are redacted if redact is true.
+func (r *Response) dump(size int, redact bool) string {
+	if r == nil || r.Response == nil {
+		return ""
+	}
+
+	var (
+		sb  strings.Builder
+		req = r.Response.Request
+	)
+
+	if req == nil {
+		req = r.Request
+	}
+
+	if req != nil {
+		sb.WriteString(dumpRequest(req, size, redact))
+		sb.WriteString("\n")
+	}
+
+	resp := *r.Response
+	if redact {
+		resp.Header = redactHeader(resp.Header)
+	}
+
+	if b, err := httputil.DumpResponse(&resp, false); err == nil {
+		sb.Write(b)
+	}
+
+	if body, err := r.peekBody(size); err == nil {
+		sb.WriteString(truncateBody(body, size, r.ContentLength))
+	} else {
+		sb.WriteString("<read body failed: " + err.Error() + ">")
+	}
+
+	return sb.String()
+}
+
+// peekBody returns up to size+1 bytes of the response body, an unread body is kept readable from the start.
+func (r *Response) peekBody(size int) ([]byte, error) {
+	if r.bodyRead {
+		return r.ReadBody()
+	}
+
+	body := r.Response.Body
+
+	buf, err := io.ReadAll(io.LimitReader(body, int64(size)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Response.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), body), body}
+
+	return buf, nil
+}
+
+// dumpRequest returns the request as it's written by the transport, with up to size bytes of its body.
+func dumpRequest(req *http.Request, size int, redact bool) string {
+	var body []byte
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			body, _ = io.ReadAll(io.LimitReader(rc, int64(size)+1))
+			_ = rc.Close()
+		}
+	}
+
+	out := req.Clone(req.Context())
+	out.Body, out.GetBody, out.ContentLength = io.NopCloser(bytes.NewReader(body)), nil, int64(len(body))
+
+	truncated := len(body) > size
+	if truncated && req.ContentLength <= 0 {
+		out.ContentLength = -1
+	}
+
+	if redact {
+		out.Header = redactHeader(out.Header)
+	}
+
+	b, err := httputil.DumpRequestOut(out, true)
+	if err != nil {
+		return "<dump request failed: " + err.Error() + ">\n"
+	}
+
+	if i := bytes.Index(b, []byte("\r\n\r\n")); i >= 0 {
+		b = b[:i+4]
+	}
+
+	// the transport writes the length of the whole body, which the dumped clone doesn't have
+	if truncated && req.ContentLength > 0 {
+		b = bytes.Replace(b, []byte("Content-Length: "+strconv.Itoa(len(body))+"\r\n"),
+			[]byte("Content-Length: "+strconv.FormatInt(req.ContentLength, 10)+"\r\n"), 1)
+	}
+
+	return string(b) + truncateBody(body, size, req.ContentLength)
+}
+
+// truncateBody returns the body truncated to size, total is the length of the whole body or -1 if unknown.
+func truncateBody(body []byte, size int, total int64) string {
+	if len(body) <= size {
+		return string(body)
+	}
+
+	if total <= int64(size) {
+		return string(body[:size]) + "\n...(truncated)"
+	}
+
+	return string(body[:size]) + "\n...(" + strconv.FormatInt(total-int64(size), 10) + " bytes truncated)"
+}
+
+// redactHeader returns a copy of the header whose sensitive values, as the logging middleware redacts them, are redacted.
+func redactHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range defaultRedactHeaders {
+		if values, ok := header[key]; ok {
+			for i := range values {
+				values[i] = redacted
+			}
+		}
+	}
+
+	return header
+}
+
+// SetDebug Set whether each request and response are dumped to the writer, os.Stderr by default.
+// The Authorization, Cookie, Set-Cookie and Proxy-Authorization headers are redacted as the logging middleware does.
+func (c *Client) SetDebug(enable bool, w ...io.Writer) {
+	c.debug = nil
+	if !enable {
+		return
+	}
+
+	c.debug = os.Stderr
+	if len(w) > 0 && w[0] != nil {
+		c.debug = w[0]
+	}
+}
+
+// dump write the request and its response or error to the debug writer.
+func (e *executor) dump(resp *Response, err error) {
+	w := e.client.debug
+	if w == nil {
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintf(w, "%s\n<error: %s>\n\n", dumpRequest(e.request, defaultDumpBodySize, true), err)
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n\n", resp.dump(defaultDumpBodySize, true))
+}
+
+// ToCurl Returns a curl command line which sends the request, the arguments are quoted for POSIX shells.
+// The files of uploads are referenced by their paths, and json or form bodies are sent as they are.
+func ToCurl(req *http.Request) (string, error) {
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+
+		body, err = io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	args := []string{"curl"}
+
+	switch {
+	case req.Method == MethodHead:
+		args = append(args, "-I")
+	case req.Method != MethodGet && req.Method != "" || len(body) > 0:
+		args = append(args, "-X", req.Method)
+	}
+
+	args = append(args, shellQuote(req.URL.String()))
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var (
+		mediaType, params, _ = mime.ParseMediaType(req.Header.Get(HeaderContentType))
+		isMultipart          = mediaType == "multipart/form-data"
+	)
+
+	for _, key := range keys {
+		if isMultipart && key == HeaderContentType {
+			continue
+		}
+
+		for _, value := range req.Header[key] {
+			if key == HeaderCookie {
+				args = append(args, "-b", shellQuote(value))
+			} else {
+				args = append(args, "-H", shellQuote(key+": "+value))
+			}
+		}
+	}
+
+	if req.Host != "" && req.Host != req.URL.Host {
+		args = append(args, "-H", shellQuote("Host: "+req.Host))
+	}
+
+	if len(body) > 0 {
+		if isMultipart {
+			forms, err := curlForms(req, body, params["boundary"])
+			if err != nil {
+				return "", err
+			}
+			args = append(args, forms...)
+		} else {
+			args = append(args, "--data-binary", shellQuote(string(body)))
+		}
+	}
+
+	return strings.Join(args, " "), nil
+}
+
+// curlForms returns the -F arguments of a multipart body.
+func curlForms(req *http.Request, body []byte, boundary string) ([]string, error) {
+	var (
+		args   []string
+		files  fileset
+		reader = multipart.NewReader(bytes.NewReader(body), boundary)
+		used   = make(map[string]int)
+	)
+
+	if v, ok := req.Context().Value(uploadFilesKey).(fileset); ok {
+		files = v
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := part.FormName()
+
+		if filename := part.FileName(); filename != "" {
+			arg := name + "=@" + filename
+			if paths := files[name]; used[name] < len(paths) {
+				path := paths[used[name]]
+				used[name]++
+
+				if arg = name + "=@" + path; filepath.Base(path) != filename {
+					arg += ";filename=" + filename
+				}
+			}
+
+			args = append(args, "-F", shellQuote(arg))
+			continue
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		ct := part.Header.Get(HeaderContentType)
+		if ct != "" && !strings.ContainsAny(string(value), `;"`) && !strings.HasPrefix(string(value), "@") && !strings.HasPrefix(string(value), "<") {
+			args = append(args, "-F", shellQuote(name+"="+string(value)+";type="+ct))
+		} else {
+			args = append(args, "--form-string", shellQuote(name+"="+string(value)))
+		}
+	}
+
+	return args, nil
+}
+
+// shellQuote quote s for POSIX shells, non-printable or invalid utf-8 strings use the $'...' form.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@,+%") == "" {
+		return s
+	}
+
+	printable := utf8.ValidString(s)
+	for _, r := range s {
+		if r < 0x20 && r != '\n' && r != '\t' || r == 0x7f {
+			printable = false
+			break
+		}
+	}
+
+	if printable {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("$'")
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' || c == '\'':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteString("'")
+
+	return sb.String()
+}
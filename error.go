@@ -0,0 +1,91 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+const statusErrorSnippetSize = 512
+
+// StatusError is returned when the status of a response is not 2xx and status checking is enabled.
+// A problem details response is returned as a *Problem wrapping the *StatusError.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Snippet    string      // the beginning of the response body.
+	Value      interface{} // the error struct decoded from the response body, nil if not registered.
+	Response   *Response
+}
+
+func (e *StatusError) Error() string {
+	if e.Snippet == "" {
+		return fmt.Sprintf("http: %s %s: %s", e.Method, e.URL, e.Status)
+	}
+
+	return fmt.Sprintf("http: %s %s: %s: %s", e.Method, e.URL, e.Status, e.Snippet)
+}
+
+// Unwrap Returns the decoded error struct if it implements error.
+func (e *StatusError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// checkStatus turn a non-2xx response into a *StatusError.
+func (e *executor) checkStatus(resp *Response) error {
+	if resp == nil || resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	result := e.errorResult
+	switch {
+	case result != nil:
+	case e.client.errorValue != nil:
+		// the registered value is reset, so no field of a previous failure is left
+		result = e.client.errorValue
+		rv := reflect.ValueOf(result).Elem()
+		rv.Set(reflect.Zero(rv.Type()))
+	case e.client.errorType != nil:
+		result = reflect.New(e.client.errorType).Interface()
+	}
+
+	if result == nil && !e.statusError && !e.client.statusError {
+		return nil
+	}
+
+	req := resp.Request
+	if req == nil {
+		req = e.request
+	}
+
+	buf, _ := resp.ReadBody()
+
+	err := &StatusError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Snippet:    snippet(buf, statusErrorSnippetSize),
+		Response:   resp,
+	}
+
+	if result != nil && len(buf) > 0 {
+		if resp.ScanBody(result) == nil {
+			err.Value = result
+		}
+	}
+
+	if problem := err.problem(); problem != nil {
+		return problem
+	}
+
+	return err
+}
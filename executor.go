@@ -2,8 +2,9 @@ package http
 
 import (
 	"context"
+	"fmt"
 	"net/http"
-	"regexp"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -11,11 +12,28 @@ import (
 type Request interface {
 	Next() (*Response, error)
 	Request() *http.Request
+	// ToCurl returns a curl command line which sends the request as it is at this point of the middlewares.
+	ToCurl() (string, error)
+	// WrapAttempt wraps each attempt to send the request, including the retries, eg: to trace or fail them.
+	// The wrappers added first are the outer ones, and each one sends the attempt by calling next,
+	// with the request or a request derived from it, eg: by WithContext.
+	WrapAttempt(wrap AttemptWrapper)
 }
 
+// AttemptFunc sends one attempt of a request.
+type AttemptFunc = func(req *http.Request) (*http.Response, error)
+
+// AttemptWrapper wraps the attempts of a request, see Request.WrapAttempt.
+type AttemptWrapper = func(req *http.Request, next AttemptFunc) (*http.Response, error)
+
+const attemptsKey = "__httpClientAttemptsKey"
+
 type executor struct {
-	client  *Client
-	request *http.Request
+	client      *Client
+	request     *http.Request
+	statusError bool
+	errorResult interface{}
+	wrappers    []AttemptWrapper
 }
 
 func (e *executor) Next() (*Response, error) {
@@ -32,8 +50,18 @@ func (e *executor) Request() *http.Request {
 	return e.request
 }
 
+func (e *executor) ToCurl() (string, error) {
+	return ToCurl(e.request)
+}
+
+func (e *executor) WrapAttempt(wrap AttemptWrapper) {
+	if wrap != nil {
+		e.wrappers = append(e.wrappers, wrap)
+	}
+}
+
 func (e *executor) call(req *http.Request) (resp *Response, err error) {
-	e.request = req
+	e.request = req.WithContext(context.WithValue(req.Context(), attemptsKey, new(int)))
 	if middlewares := e.client.getMiddlewares(); len(middlewares) > 0 {
 		handlers := make([]MiddlewareFunc, 0, len(middlewares)+1)
 		handlers = append(handlers, e.client.getMiddlewares()...)
@@ -50,12 +78,18 @@ func (e *executor) call(req *http.Request) (resp *Response, err error) {
 		resp, err = e.doRequest()
 	}
 
+	e.dump(resp, err)
+
+	if err == nil {
+		err = e.checkStatus(resp)
+	}
+
 	return
 }
 
 // nitiate an HTTP request and return the response data.
 func (e *executor) doRequest() (resp *Response, err error) {
-	resp = &Response{Request: e.request}
+	resp = &Response{Request: e.request, codecs: e.client.codecs}
 
 	defer func() {
 		if err != nil {
@@ -63,8 +97,15 @@ func (e *executor) doRequest() (resp *Response, err error) {
 		}
 	}()
 
-	for {
-		resp.Response, err = e.client.Do(e.request)
+	attempts, _ := e.request.Context().Value(attemptsKey).(*int)
+	if attempts == nil {
+		attempts = new(int)
+	}
+
+	for retries := e.client.retryCount; ; retries-- {
+		*attempts++
+
+		resp.Response, err = e.send()
 		if err == nil {
 			break
 		}
@@ -73,11 +114,15 @@ func (e *executor) doRequest() (resp *Response, err error) {
 			resp.Response.Body.Close()
 		}
 
-		if e.client.retryCount <= 0 {
+		if retries <= 0 {
 			break
 		}
 
-		e.client.retryCount--
+		if e.request.GetBody != nil {
+			if e.request.Body, err = e.request.GetBody(); err != nil {
+				break
+			}
+		}
 
 		if e.client.retryInterval > 0 {
 			time.Sleep(e.client.retryInterval)
@@ -87,15 +132,118 @@ func (e *executor) doRequest() (resp *Response, err error) {
 	return
 }
 
-func (e *executor) makeUrl(url string) string {
-	if e.client.baseUrl == "" {
-		return url
+// send the request once, through the attempt wrappers of the middlewares.
+func (e *executor) send() (*http.Response, error) {
+	send := e.client.Do
+	for i := len(e.wrappers) - 1; i >= 0; i-- {
+		wrap, next := e.wrappers[i], send
+		send = func(req *http.Request) (*http.Response, error) {
+			return wrap(req, next)
+		}
+	}
+
+	return send(e.request)
+}
+
+// requestAttempts returns how many times the request was sent, including retries.
+func requestAttempts(req *http.Request) int {
+	if attempts, ok := req.Context().Value(attemptsKey).(*int); ok {
+		return *attempts
+	}
+
+	return 0
+}
+
+// makeUrl resolve the url against the base url of the client.
+// The path of the base url is a prefix for relative urls, so both "users" and "/users"
+// resolve to https://example.com/api/v2/users against https://example.com/api/v2.
+// Dot segments, scheme-relative and absolute urls follow the rules of RFC 3986,
+// and the query of the base url is merged when the resolved url stays on the base scheme and host.
+// A url with another scheme, eg: http against https, or with userinfo is external as well as another host.
+func (e *executor) makeUrl(rawUrl string) (string, error) {
+	base := e.client.base
+	if base == nil {
+		return rawUrl, nil
 	}
 
-	matched, err := regexp.MatchString(`(?i)^(http|https)://[-a-zA-Z0-9+&@#/%?=~_|,!:.;]*`, url)
-	if err == nil && matched {
-		return url
+	ref, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
 	}
 
-	return strings.TrimRight(e.client.baseUrl, "/") + "/" + strings.TrimLeft(url, "/")
+	if !ref.IsAbs() && ref.Host == "" {
+		ref.Path = strings.TrimLeft(ref.Path, "/")
+		ref.RawPath = strings.TrimLeft(ref.RawPath, "/")
+	}
+
+	dir := *base
+	if !strings.HasSuffix(dir.Path, "/") {
+		dir.Path += "/"
+		if dir.RawPath != "" {
+			dir.RawPath += "/"
+		}
+	}
+
+	u := dir.ResolveReference(ref)
+
+	if !strings.EqualFold(u.Host, base.Host) || !strings.EqualFold(u.Scheme, base.Scheme) || ref.User != nil {
+		if e.client.forbidExternal {
+			return "", fmt.Errorf("http: url %q escapes the base url %q", rawUrl, base.Scheme+"://"+base.Host)
+		}
+
+		return u.String(), nil
+	}
+
+	return mergeQuery(u.String(), base.Query(), false)
+}
+
+// mergeQuery merge the query values into the url, the existing query is kept as it is and the values are appended.
+// The values replace the existing ones with the same key when override is true, otherwise they are ignored.
+func mergeQuery(rawUrl string, values url.Values, override bool) (string, error) {
+	if len(values) == 0 {
+		return rawUrl, nil
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		pairs    []string
+		existing = make(map[string]bool)
+	)
+
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		key, _, _ := strings.Cut(pair, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+
+		if _, ok := values[key]; ok && override {
+			continue
+		}
+
+		existing[key] = true
+		pairs = append(pairs, pair)
+	}
+
+	added := make(url.Values, len(values))
+	for key, items := range values {
+		if !existing[key] {
+			added[key] = items
+		}
+	}
+
+	if encoded := added.Encode(); encoded != "" {
+		pairs = append(pairs, encoded)
+	}
+
+	u.RawQuery = strings.Join(pairs, "&")
+
+	return u.String(), nil
 }
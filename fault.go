@@ -0,0 +1,319 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderFaultInjected is set on the synthetic responses of FaultRule.Status.
+const HeaderFaultInjected = "X-Fault-Injected"
+
+// FaultRule is the faults injected into the requests it matches.
+// The faults are rolled for each attempt of a request, so retries may succeed after a fault.
+type FaultRule struct {
+	// Host matches the host of the url, with or without the port, empty for any host.
+	Host string
+	// Method matches the method of the request, empty for any method.
+	Method string
+	// Path is a glob matched against the path of the url template, eg: /users/{id},
+	// or the path of the url, eg: /users/*. Empty for any path.
+	Path string
+
+	// Latency is added to an attempt with LatencyProbability, from 0 to 1.
+	Latency            time.Duration
+	LatencyProbability float64
+	// Timeout fails an attempt with a timeout error after waiting Timeout, with TimeoutProbability.
+	Timeout            time.Duration
+	TimeoutProbability float64
+	// Status answers an attempt with a synthetic response of the status without sending it, with StatusProbability.
+	Status            int
+	StatusProbability float64
+	// DropAfter is the size of the response body read before the connection drops with io.ErrUnexpectedEOF,
+	// which happens with DropProbability.
+	DropAfter       int
+	DropProbability float64
+	// TruncateAt is the size the response body is cut to with TruncateProbability, the body then ends normally.
+	TruncateAt          int
+	TruncateProbability float64
+}
+
+type FaultOptions struct {
+	// Rules are matched in order, the first one matching a request injects its faults.
+	Rules []FaultRule
+	// Seed makes the injection reproducible for requests sent sequentially, a zero seed is random.
+	Seed int64
+}
+
+// NewFaultMiddleware Create a middleware which injects faults into the attempts of the requests for chaos testing.
+// The faults happen below all the middlewares, which observe them as the errors or the responses of the transport.
+// A rule whose probability of a fault is set without the fault, eg: StatusProbability without Status, is rejected.
+func NewFaultMiddleware(opts ...*FaultOptions) (MiddlewareFunc, error) {
+	var (
+		f    = &faults{}
+		seed int64
+	)
+
+	if len(opts) > 0 && opts[0] != nil {
+		f.rules, seed = append(f.rules, opts[0].Rules...), opts[0].Seed
+	}
+
+	for i := range f.rules {
+		if err := f.rules[i].validate(); err != nil {
+			return nil, fmt.Errorf("http: invalid fault rule %d: %w", i, err)
+		}
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	f.rand = rand.New(rand.NewSource(seed))
+
+	return f.handle, nil
+}
+
+type faults struct {
+	rules []FaultRule
+	mu    sync.Mutex
+	rand  *rand.Rand
+}
+
+// fault is the injector of a request.
+type fault struct {
+	*faults
+	rule *FaultRule
+}
+
+func (f *faults) handle(r Request) (*Response, error) {
+	req := r.Request()
+
+	for i := range f.rules {
+		if rule := &f.rules[i]; rule.match(req) {
+			r.WrapAttempt((&fault{faults: f, rule: rule}).do)
+			break
+		}
+	}
+
+	return r.Next()
+}
+
+// validate returns an error if a probability is out of [0, 1], or is set for a fault which can't happen.
+func (rule *FaultRule) validate() error {
+	for name, p := range map[string]float64{
+		"LatencyProbability":  rule.LatencyProbability,
+		"TimeoutProbability":  rule.TimeoutProbability,
+		"StatusProbability":   rule.StatusProbability,
+		"DropProbability":     rule.DropProbability,
+		"TruncateProbability": rule.TruncateProbability,
+	} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("%s %v is out of [0, 1]", name, p)
+		}
+	}
+
+	switch {
+	case rule.LatencyProbability > 0 && rule.Latency <= 0:
+		return errors.New("LatencyProbability without Latency")
+	case rule.StatusProbability > 0 && (rule.Status < 100 || rule.Status > 999):
+		return fmt.Errorf("StatusProbability with invalid Status %d", rule.Status)
+	case rule.Timeout < 0:
+		return errors.New("negative Timeout")
+	case rule.DropAfter < 0:
+		return errors.New("negative DropAfter")
+	case rule.TruncateAt < 0:
+		return errors.New("negative TruncateAt")
+	}
+
+	if rule.Path != "" {
+		if _, err := path.Match(rule.Path, ""); err != nil {
+			return fmt.Errorf("invalid Path %q: %w", rule.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// match reports whether the rule matches the request.
+func (rule *FaultRule) match(req *http.Request) bool {
+	if rule.Method != "" && !strings.EqualFold(rule.Method, req.Method) {
+		return false
+	}
+
+	if rule.Host != "" && !strings.EqualFold(rule.Host, req.URL.Host) && !strings.EqualFold(rule.Host, req.URL.Hostname()) {
+		return false
+	}
+
+	if rule.Path == "" {
+		return true
+	}
+
+	if ok, _ := path.Match(rule.Path, req.URL.Path); ok {
+		return true
+	}
+
+	template := UrlTemplate(req)
+	if u, err := url.Parse(template); err == nil {
+		template = u.Path
+	}
+
+	ok, _ := path.Match(strings.TrimPrefix(rule.Path, "/"), strings.TrimPrefix(template, "/"))
+
+	return ok
+}
+
+// roll returns whether each fault of the rule happens to an attempt, in the order of the fields.
+// The random numbers are always drawn for all the faults, to keep a seeded sequence independent of the results.
+func (f *fault) roll() (latency, timeout, status, drop, truncate bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	latency = f.rand.Float64() < f.rule.LatencyProbability
+	timeout = f.rand.Float64() < f.rule.TimeoutProbability
+	status = f.rand.Float64() < f.rule.StatusProbability
+	drop = f.rand.Float64() < f.rule.DropProbability
+	truncate = f.rand.Float64() < f.rule.TruncateProbability
+
+	return
+}
+
+// do send an attempt of the request by send, with the faults rolled for it.
+func (f *fault) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	latency, timeout, status, drop, truncate := f.roll()
+
+	if latency {
+		if err := sleep(req.Context(), f.rule.Latency); err != nil {
+			return nil, err
+		}
+	}
+
+	if timeout {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		if err := sleep(req.Context(), f.rule.Timeout); err != nil {
+			return nil, err
+		}
+
+		return nil, &url.Error{Op: urlErrorOp(req.Method), URL: req.URL.String(), Err: faultTimeoutError{}}
+	}
+
+	if status {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		text := http.StatusText(f.rule.Status)
+
+		return &http.Response{
+			Status:        strconv.Itoa(f.rule.Status) + " " + text,
+			StatusCode:    f.rule.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{HeaderContentType: {"text/plain; charset=utf-8"}, HeaderFaultInjected: {"status"}},
+			Body:          io.NopCloser(bytes.NewReader([]byte(text))),
+			ContentLength: int64(len(text)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := send(req)
+	if err != nil || (!drop && !truncate) {
+		return resp, err
+	}
+
+	if drop {
+		resp.Body = &faultBody{ReadCloser: resp.Body, remain: int64(f.rule.DropAfter), err: io.ErrUnexpectedEOF}
+	} else {
+		resp.Body = &faultBody{ReadCloser: resp.Body, remain: int64(f.rule.TruncateAt), err: io.EOF}
+
+		if resp.ContentLength > int64(f.rule.TruncateAt) {
+			resp.ContentLength = int64(f.rule.TruncateAt)
+			resp.Header.Set("Content-Length", strconv.Itoa(f.rule.TruncateAt))
+		}
+	}
+
+	return resp, nil
+}
+
+// faultBody ends a body with err after remain bytes.
+type faultBody struct {
+	io.ReadCloser
+	remain int64
+	err    error
+}
+
+func (b *faultBody) Read(p []byte) (int, error) {
+	if b.remain <= 0 {
+		return 0, b.err
+	}
+
+	if int64(len(p)) > b.remain {
+		p = p[:b.remain]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remain -= int64(n)
+
+	return n, err
+}
+
+// faultTimeoutError is the error of an injected timeout, which is a timeout net.Error like the ones of http.Client.
+type faultTimeoutError struct{}
+
+var _ net.Error = faultTimeoutError{}
+
+func (faultTimeoutError) Error() string {
+	return "http: injected fault: timeout awaiting response headers"
+}
+
+func (faultTimeoutError) Timeout() bool {
+	return true
+}
+
+func (faultTimeoutError) Temporary() bool {
+	return true
+}
+
+func (faultTimeoutError) Is(err error) bool {
+	return err == context.DeadlineExceeded
+}
+
+// urlErrorOp returns the Op of a *url.Error of http.Client for the method, eg: Get.
+func urlErrorOp(method string) string {
+	if method == "" {
+		return "Get"
+	}
+
+	return method[:1] + strings.ToLower(method[1:])
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1 +1,8 @@
-module "github.com/dobyte/http"
\ No newline at end of file
+module github.com/dobyte/http
+
+go 1.21
+
+require (
+	golang.org/x/net v0.26.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"github.com/dobyte/http/internal/inproc"
+	"net/http"
+)
+
+// HandlerTransport is a http.RoundTripper serving the requests by a http.Handler in-process, without network.
+// The response is returned once the handler writes its header, and its body streams what the handler writes.
+// The context of the request seen by the handler is canceled with the request, eg: by the timeout of the client,
+// or when the response body is closed. A handler may hijack the connection to answer 101 Switching Protocols,
+// the body of the response is then an io.ReadWriteCloser of the upgraded connection.
+type HandlerTransport = inproc.Transport
+
+// NewHandlerTransport Create a transport serving the requests by the handler.
+func NewHandlerTransport(handler http.Handler) *HandlerTransport {
+	return &HandlerTransport{Handler: handler}
+}
+
+// SetHandler Set the client to send the requests to the handler in-process, see HandlerTransport.
+// The urls of the requests must be absolute, eg: with a base url of http://service.local.
+// The header order set by SetHeaderOrder is dropped, as the handler reads no header lines.
+func (c *Client) SetHandler(handler http.Handler) {
+	c.Transport, c.headerOrder = NewHandlerTransport(handler), nil
+}
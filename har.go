@@ -0,0 +1,283 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/dobyte/http/internal/har"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const defaultHarMaxBodySize = 1 << 20
+
+// Har is an HTTP Archive 1.2, which can be imported in the network panel of browser devtools.
+type Har = har.HAR
+
+// HarEntry is a request recorded in a Har.
+type HarEntry = har.Entry
+
+type HarRecorderOptions struct {
+	// MaxRequestBodySize is the size of the request bodies recorded, 1 MiB by default, negative to record none.
+	MaxRequestBodySize int
+	// MaxResponseBodySize is the size of the response bodies recorded, 1 MiB by default, negative to record none.
+	MaxResponseBodySize int
+	// Creator is the name of the creator of the archive, defaults to github.com/dobyte/http.
+	Creator string
+	// RedactHeaders are redacted along with Authorization, Cookie, Set-Cookie and Proxy-Authorization,
+	// as the logging middleware does, the cookies are redacted with the Cookie and Set-Cookie headers.
+	RedactHeaders []string
+	// DisableRedaction records the headers and the cookies as they are, eg: to replay a session.
+	DisableRedaction bool
+}
+
+// HarRecorder records the requests passing through its middleware, and exports them as a Har.
+// The response bodies are recorded while they are read by the caller, so streaming responses keep streaming
+// and the entry of a body read later is completed then.
+type HarRecorder struct {
+	opts    HarRecorderOptions
+	mu      sync.Mutex
+	records []*harRecord
+}
+
+type harRecord struct {
+	entry    har.Entry
+	response bool
+	timer    *har.Timer
+	start    time.Time
+	headers  time.Time
+	end      time.Time
+	body     bytes.Buffer
+	size     int64
+	complete bool
+}
+
+// NewHarRecorder Create a recorder, add its Middleware to a client to record the requests of the client.
+func NewHarRecorder(opts ...*HarRecorderOptions) *HarRecorder {
+	r := &HarRecorder{}
+	if len(opts) > 0 && opts[0] != nil {
+		r.opts = *opts[0]
+	}
+
+	if r.opts.MaxRequestBodySize == 0 {
+		r.opts.MaxRequestBodySize = defaultHarMaxBodySize
+	}
+
+	if r.opts.MaxResponseBodySize == 0 {
+		r.opts.MaxResponseBodySize = defaultHarMaxBodySize
+	}
+
+	if r.opts.Creator == "" {
+		r.opts.Creator = "github.com/dobyte/http"
+	}
+
+	return r
+}
+
+// Middleware returns the middleware recording the requests, it should be the last middleware
+// to record the requests as they are sent.
+func (r *HarRecorder) Middleware() MiddlewareFunc {
+	return r.handle
+}
+
+func (r *HarRecorder) handle(req Request) (*Response, error) {
+	var (
+		request = req.Request()
+		record  = &harRecord{timer: &har.Timer{}, start: time.Now()}
+	)
+
+	req.WrapAttempt(func(r *http.Request, next AttemptFunc) (*http.Response, error) {
+		return next(r.WithContext(httptrace.WithClientTrace(r.Context(), record.timer.Trace())))
+	})
+
+	var (
+		body []byte
+		size = request.ContentLength
+	)
+
+	if request.GetBody != nil && r.opts.MaxRequestBodySize >= 0 {
+		if rc, err := request.GetBody(); err == nil {
+			body, _ = io.ReadAll(io.LimitReader(rc, int64(r.opts.MaxRequestBodySize)))
+			if size < 0 {
+				n, _ := io.Copy(io.Discard, rc)
+				size = int64(len(body)) + n
+			}
+			_ = rc.Close()
+		}
+	}
+
+	resp, err := req.Next()
+
+	record.headers = time.Now()
+
+	wire := request
+	if resp != nil && resp.Response != nil && resp.Response.Request != nil && resp.Response.Request.URL.String() == request.URL.String() {
+		// the cookies of the jar are only added to the request sent by http.Client
+		wire = resp.Response.Request
+	}
+
+	record.entry = har.Entry{
+		StartedDateTime: record.start,
+		Request:         har.NewRequest(wire, body, max(size, 0), r.opts.MaxRequestBodySize),
+	}
+
+	if err != nil || resp == nil || resp.Response == nil {
+		record.end = record.headers
+		record.entry.Response = har.Response{
+			Cookies:     make([]har.Cookie, 0),
+			Headers:     make([]har.NameValue, 0),
+			Content:     har.Content{Size: 0, MimeType: "x-unknown"},
+			HTTPVersion: "HTTP/1.1",
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+
+		if err != nil {
+			record.entry.Error = err.Error()
+		}
+	} else {
+		record.response = true
+		record.entry.Response = har.NewResponse(resp.Response)
+		resp.Response.Body = &harBody{ReadCloser: resp.Response.Body, recorder: r, record: record}
+	}
+
+	if !r.opts.DisableRedaction {
+		record.entry.Redact(append(defaultRedactHeaders, r.opts.RedactHeaders...), redacted)
+	}
+
+	r.mu.Lock()
+	r.records = append(r.records, record)
+	r.mu.Unlock()
+
+	return resp, err
+}
+
+// harBody records a response body while it is read.
+type harBody struct {
+	io.ReadCloser
+	recorder *HarRecorder
+	record   *harRecord
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.recorder.mu.Lock()
+	defer b.recorder.mu.Unlock()
+
+	record, limit := b.record, b.recorder.opts.MaxResponseBodySize
+	if rest := limit - record.body.Len(); rest > 0 {
+		record.body.Write(p[:min(n, rest)])
+	}
+
+	record.size += int64(n)
+
+	if err == io.EOF && !record.complete {
+		record.complete, record.end = true, time.Now()
+	}
+
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	b.recorder.mu.Lock()
+	if b.record.end.IsZero() {
+		b.record.end = time.Now()
+	}
+	b.recorder.mu.Unlock()
+
+	return b.ReadCloser.Close()
+}
+
+// Har returns the archive of the requests recorded so far.
+func (r *HarRecorder) Har() *Har {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h := &Har{Log: har.Log{
+		Version: har.Version,
+		Creator: har.Creator{Name: r.opts.Creator, Version: har.Version},
+		Entries: make([]har.Entry, 0, len(r.records)),
+	}}
+
+	for _, record := range r.records {
+		entry, end := record.entry, record.end
+		if end.IsZero() {
+			end = time.Now()
+		}
+
+		if record.response {
+			if r.opts.MaxResponseBodySize >= 0 {
+				entry.Response.SetContent(record.body.Bytes(), record.size, record.complete)
+			} else {
+				entry.Response.Content.Size, entry.Response.BodySize = record.size, record.size
+			}
+		}
+
+		entry.Timings = record.timer.Timings(record.start, record.headers, end)
+		entry.Time = entry.Timings.Total()
+		entry.ServerIPAddress, entry.Connection = record.timer.Addrs()
+
+		if host, _, err := net.SplitHostPort(entry.ServerIPAddress); err == nil {
+			entry.ServerIPAddress = host
+		}
+
+		if _, port, err := net.SplitHostPort(entry.Connection); err == nil {
+			entry.Connection = port
+		}
+
+		h.Log.Entries = append(h.Log.Entries, entry)
+	}
+
+	return h
+}
+
+// Len returns the number of requests recorded.
+func (r *HarRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.records)
+}
+
+// Reset Discard the requests recorded.
+func (r *HarRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = nil
+}
+
+// Save Write the archive as indented json to w.
+func (r *HarRecorder) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r.Har())
+}
+
+// SaveFile Write the archive to a .har file, the file is replaced atomically.
+func (r *HarRecorder) SaveFile(filename string) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+
+	if err = r.Save(f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), filename)
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/dobyte/http/internal/headerorder"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// headerOrder is the order of the header lines written by the transport of a client.
+type headerOrder struct {
+	mu   sync.RWMutex
+	keys []string
+	// transport is the one cloned into the ordered transport, which the sessions clone again.
+	transport *http.Transport
+}
+
+func (o *headerOrder) get() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return o.keys
+}
+
+func (o *headerOrder) set(keys []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.keys = append([]string(nil), keys...)
+}
+
+// SetHeaderOrder Set the order of the header lines on the wire, for servers and WAFs fingerprinting on it.
+// The headers not in keys are written after the ordered ones, and calling it without keys restores the default order.
+// Since net/http writes headers sorted by key, the transport of the client must be a *http.Transport,
+// which is replaced by a clone writing requests over HTTP/1.1.
+// The sessions derived from the client afterwards start with a copy of the order and a transport of their own,
+// so the order of the client and of each session is changed independently.
+func (c *Client) SetHeaderOrder(keys ...string) error {
+	if c.headerOrder != nil {
+		c.headerOrder.set(keys)
+		return nil
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	transport, ok := c.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("http: header order requires the transport to be a *http.Transport")
+	}
+
+	order := &headerOrder{transport: transport}
+	order.set(keys)
+
+	c.Transport, c.headerOrder = orderedTransport(transport, order), order
+
+	return nil
+}
+
+// clone returns a copy of the order with an ordered transport of its own.
+func (o *headerOrder) clone() (*headerOrder, *http.Transport) {
+	order := &headerOrder{transport: o.transport}
+	order.set(o.get())
+
+	return order, orderedTransport(o.transport, order)
+}
+
+// orderedTransport clone the transport, whose connections reorder the header lines of requests.
+func orderedTransport(t *http.Transport, order *headerOrder) *http.Transport {
+	var (
+		clone   = t.Clone()
+		dial    = t.DialContext
+		dialTLS = t.DialTLSContext
+	)
+
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+
+	clone.ForceAttemptHTTP2 = false
+	clone.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+
+	clone.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		return headerorder.Wrap(conn, order.get), nil
+	}
+
+	clone.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if dialTLS != nil {
+			conn, err := dialTLS(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+
+			return headerorder.Wrap(conn, order.get), nil
+		}
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		config := &tls.Config{}
+		if t.TLSClientConfig != nil {
+			config = t.TLSClientConfig.Clone()
+		}
+
+		if config.ServerName == "" {
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				config.ServerName = host
+			}
+		}
+		config.NextProtos = []string{"http/1.1"}
+
+		tlsConn := tls.Client(conn, config)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		return headerorder.Wrap(tlsConn, order.get), nil
+	}
+
+	return clone
+}
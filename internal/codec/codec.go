@@ -0,0 +1,171 @@
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"github.com/dobyte/http/internal/query"
+	"github.com/dobyte/http/internal/xconv"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	MediaTypeJson           = "application/json"
+	MediaTypeXml            = "application/xml"
+	MediaTypeFormUrlEncoded = "application/x-www-form-urlencoded"
+)
+
+// Codec encodes and decodes values of a media type.
+type Codec interface {
+	// ContentType returns the media type handled by the codec, eg: application/json.
+	ContentType() string
+	// Marshal encodes v into bytes.
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data into the value pointed to by v.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var defaultRegistry = NewRegistry()
+
+type Registry struct {
+	rw     sync.RWMutex
+	codecs map[string]Codec
+}
+
+// NewRegistry create a registry with the builtin json, xml and form codecs.
+func NewRegistry() *Registry {
+	r := &Registry{codecs: make(map[string]Codec)}
+	r.Register(jsonCodec{}, xmlCodec{}, NewFormCodec(&query.Encoder{}))
+
+	return r
+}
+
+// Default returns the registry used when none is given.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register registers codecs by their media types, replacing the existing ones.
+func (r *Registry) Register(codecs ...Codec) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	for _, c := range codecs {
+		r.codecs[MediaType(c.ContentType())] = c
+	}
+}
+
+// Lookup find a codec by a Content-Type value.
+// Structured syntax suffixes like +json and +xml fall back to the json and xml codecs.
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	if r == nil {
+		r = defaultRegistry
+	}
+
+	mediaType := MediaType(contentType)
+	if mediaType == "" {
+		return nil, false
+	}
+
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	if c, ok := r.codecs[mediaType]; ok {
+		return c, true
+	}
+
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		c, ok := r.codecs[MediaTypeJson]
+		return c, ok
+	case strings.HasSuffix(mediaType, "+xml"), mediaType == "text/xml":
+		c, ok := r.codecs[MediaTypeXml]
+		return c, ok
+	}
+
+	return nil, false
+}
+
+// Decode decodes b into v according to the codec of contentType.
+// Scalar destinations and unknown media types are converted by xconv.Scan.
+func (r *Registry) Decode(b []byte, v interface{}, contentType string) error {
+	if c, ok := r.Lookup(contentType); ok && isComplex(v) {
+		return c.Unmarshal(b, v)
+	}
+
+	if s, ok := v.(*string); ok && strings.HasPrefix(MediaType(contentType), "text/") {
+		*s = string(b)
+		return nil
+	}
+
+	return xconv.Scan(b, v)
+}
+
+// MediaType returns the lower-cased media type of a Content-Type value without parameters.
+func MediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// isComplex determine if v is a pointer to a struct, map, slice or array, or to pointers of them, eg: GetAs[*T].
+func isComplex(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false
+	}
+
+	rt := rv.Type().Elem()
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	switch rt.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct:
+		return true
+	}
+
+	return false
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return MediaTypeJson }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string { return MediaTypeXml }
+
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) { return xml.Marshal(v) }
+
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error { return xml.Unmarshal(data, v) }
+
+type formCodec struct {
+	encoder *query.Encoder
+}
+
+// NewFormCodec create an url encoded form codec with the encoder.
+func NewFormCodec(encoder *query.Encoder) Codec {
+	return formCodec{encoder: encoder}
+}
+
+func (formCodec) ContentType() string { return MediaTypeFormUrlEncoded }
+
+func (c formCodec) Marshal(v interface{}) ([]byte, error) {
+	s, err := c.encoder.Encode(v)
+	return []byte(s), err
+}
+
+func (formCodec) Unmarshal(data []byte, v interface{}) error { return decodeForm(data, v) }
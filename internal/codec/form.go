@@ -0,0 +1,174 @@
+package codec
+
+import (
+	"fmt"
+	"github.com/dobyte/http/internal/xconv"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decodeForm decodes an url encoded form into url.Values, maps or tagged structs.
+func decodeForm(b []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+		return nil
+	case *map[string][]string:
+		*v = values
+		return nil
+	case *map[string]string:
+		if *v == nil {
+			*v = make(map[string]string, len(values))
+		}
+		for key := range values {
+			(*v)[key] = values.Get(key)
+		}
+		return nil
+	case *map[string]interface{}:
+		if *v == nil {
+			*v = make(map[string]interface{}, len(values))
+		}
+		for key, items := range values {
+			if len(items) == 1 {
+				(*v)[key] = items[0]
+			} else {
+				(*v)[key] = items
+			}
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return xconv.Scan(b, v)
+	}
+
+	return decodeFormStruct(values, rv.Elem())
+}
+
+// decodeFormStruct fill the fields of struct by form values.
+func decodeFormStruct(values url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			if err := decodeFormStruct(values, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := fieldName(field, "form", "url", "json")
+		if name == "-" {
+			continue
+		}
+
+		items, ok := values[name]
+		if !ok || len(items) == 0 {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+			for n, item := range items {
+				if err := setValue(slice.Index(n), item); err != nil {
+					return fmt.Errorf("form field %q: %w", name, err)
+				}
+			}
+			fv.Set(slice)
+			continue
+		}
+
+		if err := setValue(fv, items[0]); err != nil {
+			return fmt.Errorf("form field %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// fieldName returns the name of field according to the first tag found.
+func fieldName(field reflect.StructField, tags ...string) string {
+	for _, tag := range tags {
+		if value, ok := field.Tag.Lookup(tag); ok {
+			if name := strings.Split(value, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+
+	return field.Name
+}
+
+// setValue set a string value to a reflected value.
+func setValue(rv reflect.Value, s string) error {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(n)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			rv.SetBytes([]byte(s))
+			return nil
+		}
+		fallthrough
+	default:
+		return fmt.Errorf("unsupported type %s", rv.Type())
+	}
+
+	return nil
+}
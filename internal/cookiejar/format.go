@@ -0,0 +1,125 @@
+package cookiejar
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	netscapeHeader   = "# Netscape HTTP Cookie File"
+	netscapeHttpOnly = "#HttpOnly_"
+)
+
+func readJSON(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("cookiejar: decode json: %w", err)
+	}
+
+	return entries, nil
+}
+
+func writeJSON(w io.Writer, entries []Entry) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(entries)
+}
+
+// readNetscape read the cookies.txt format, each line has seven tab separated fields:
+// domain, include subdomains, path, secure, expires in unix seconds (0 for a session cookie), name and value.
+func readNetscape(r io.Reader) ([]Entry, error) {
+	var (
+		entries []Entry
+		scanner = bufio.NewScanner(r)
+		line    int
+	)
+
+	for scanner.Scan() {
+		line++
+
+		var (
+			text     = strings.TrimRight(scanner.Text(), "\r")
+			httpOnly bool
+		)
+
+		if strings.HasPrefix(text, netscapeHttpOnly) {
+			text, httpOnly = strings.TrimPrefix(text, netscapeHttpOnly), true
+		}
+
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) == 6 {
+			fields = append(fields, "")
+		}
+
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("cookiejar: line %d: want 7 tab separated fields, got %d", line, len(fields))
+		}
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cookiejar: line %d: invalid expires %q", line, fields[4])
+		}
+
+		e := Entry{
+			Domain:   strings.TrimPrefix(fields[0], "."),
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+			Name:     fields[5],
+			Value:    fields[6],
+		}
+
+		if expires > 0 {
+			e.Expires, e.Persistent = time.Unix(expires, 0), true
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cookiejar: read cookies.txt: %w", err)
+	}
+
+	return entries, nil
+}
+
+func writeNetscape(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "%s\n# This file was generated by github.com/dobyte/http.\n\n", netscapeHeader)
+
+	for _, e := range entries {
+		var (
+			domain     = e.Domain
+			subdomains = "FALSE"
+			expires    int64
+		)
+
+		if !e.HostOnly {
+			domain, subdomains = "."+domain, "TRUE"
+		}
+
+		if e.HttpOnly {
+			domain = netscapeHttpOnly + domain
+		}
+
+		if e.Persistent {
+			expires = e.Expires.Unix()
+		}
+
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", domain, subdomains, e.Path, strings.ToUpper(strconv.FormatBool(e.Secure)), expires, e.Name, e.Value)
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,562 @@
+// Package cookiejar implements a http.CookieJar which is saved to and loaded from disk.
+package cookiejar
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/publicsuffix"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format is the file format of a jar.
+type Format int
+
+const (
+	// FormatAuto chooses FormatNetscape for a .txt file, FormatJSON otherwise.
+	FormatAuto Format = iota
+	// FormatJSON is a json array of entries.
+	FormatJSON
+	// FormatNetscape is the cookies.txt format of curl, wget and browser extensions.
+	FormatNetscape
+)
+
+// PublicSuffixList provides the public suffix of a domain, eg: co.uk for www.example.co.uk.
+type PublicSuffixList interface {
+	PublicSuffix(domain string) string
+	String() string
+}
+
+type Options struct {
+	// Filename is loaded by New if it exists, and saved by Save, the autosave and Close.
+	Filename string
+	// Format is the format of Filename.
+	Format Format
+	// AutosaveInterval saves the jar to Filename periodically when it was changed, zero disables it.
+	AutosaveInterval time.Duration
+	// PersistSessionCookies saves the cookies without Expires or MaxAge,
+	// which are not saved by default like a browser drops them on restart.
+	PersistSessionCookies bool
+	// PublicSuffixList rejects cookies set for a public suffix, eg: Domain=co.uk.
+	// It defaults to the builtin list of publicsuffix.org.
+	PublicSuffixList PublicSuffixList
+}
+
+// Entry is a cookie stored in a jar.
+type Entry struct {
+	Name       string        `json:"name"`
+	Value      string        `json:"value"`
+	Domain     string        `json:"domain"`
+	Path       string        `json:"path"`
+	Expires    time.Time     `json:"expires,omitempty"`
+	Secure     bool          `json:"secure,omitempty"`
+	HttpOnly   bool          `json:"httpOnly,omitempty"`
+	HostOnly   bool          `json:"hostOnly,omitempty"`
+	SameSite   http.SameSite `json:"sameSite,omitempty"`
+	Persistent bool          `json:"persistent,omitempty"`
+	Creation   time.Time     `json:"creation"`
+	LastAccess time.Time     `json:"lastAccess"`
+
+	seq uint64
+}
+
+func (e *Entry) id() string {
+	return e.Domain + ";" + e.Path + ";" + e.Name
+}
+
+func (e *Entry) expired(now time.Time) bool {
+	return e.Persistent && !e.Expires.After(now)
+}
+
+// Jar is a http.CookieJar which can be saved to and loaded from disk in JSON or Netscape format.
+type Jar struct {
+	mu      sync.Mutex
+	opts    Options
+	psl     PublicSuffixList
+	entries map[string]*Entry
+	seq     uint64
+	dirty   bool
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New create a jar, it loads Options.Filename if it exists and starts the autosave.
+func New(opts *Options) (*Jar, error) {
+	j := &Jar{entries: make(map[string]*Entry)}
+
+	if opts != nil {
+		j.opts = *opts
+	}
+
+	if j.psl = j.opts.PublicSuffixList; j.psl == nil {
+		j.psl = publicsuffix.List
+	}
+
+	if j.opts.Filename != "" {
+		if err := j.LoadFile(j.opts.Filename, j.opts.Format); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		j.dirty = false
+	}
+
+	if j.opts.AutosaveInterval > 0 && j.opts.Filename != "" {
+		j.stop, j.done = make(chan struct{}), make(chan struct{})
+		go j.autosave(j.opts.AutosaveInterval)
+	}
+
+	return j, nil
+}
+
+// SetCookies implements http.CookieJar, it stores the cookies of a response from the url.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss" {
+		return
+	}
+
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return
+	}
+
+	var (
+		now    = time.Now()
+		secure = u.Scheme == "https" || u.Scheme == "wss"
+	)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, cookie := range cookies {
+		e, remove, err := j.newEntry(cookie, host, defaultPath(u.Path), secure, now)
+		if err != nil {
+			continue
+		}
+
+		id := e.id()
+		if remove {
+			if _, ok := j.entries[id]; ok {
+				delete(j.entries, id)
+				j.dirty = true
+			}
+			continue
+		}
+
+		if old, ok := j.entries[id]; ok {
+			e.Creation, e.seq = old.Creation, old.seq
+		} else {
+			e.seq = j.seq
+			j.seq++
+		}
+
+		j.entries[id], j.dirty = e, true
+	}
+}
+
+// Cookies implements http.CookieJar, it returns the cookies to send to the url,
+// ordered by RFC 6265: cookies with longer paths first, then the ones created earlier.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil
+	}
+
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		now      = time.Now()
+		secure   = u.Scheme == "https" || u.Scheme == "wss"
+		path     = u.Path
+		selected []*Entry
+	)
+
+	if path == "" {
+		path = "/"
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for id, e := range j.entries {
+		if e.expired(now) {
+			delete(j.entries, id)
+			j.dirty = true
+			continue
+		}
+
+		if e.Secure && !secure || !e.domainMatch(host) || !pathMatch(path, e.Path) {
+			continue
+		}
+
+		e.LastAccess = now
+		selected = append(selected, e)
+	}
+
+	sort.Slice(selected, func(i, k int) bool {
+		if len(selected[i].Path) != len(selected[k].Path) {
+			return len(selected[i].Path) > len(selected[k].Path)
+		}
+		return selected[i].seq < selected[k].seq
+	})
+
+	cookies := make([]*http.Cookie, len(selected))
+	for i, e := range selected {
+		cookies[i] = &http.Cookie{Name: e.Name, Value: e.Value}
+	}
+
+	return cookies
+}
+
+// Entries returns the cookies of the jar which are not expired, in the order they were created.
+func (j *Jar) Entries() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.entriesLocked(time.Now(), true)
+}
+
+// Add add entries into the jar, replacing the ones with the same name, domain and path.
+// The expired entries and the domain entries of a public suffix are ignored.
+func (j *Jar) Add(entries ...Entry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.addLocked(entries, time.Now())
+}
+
+// Clear delete all cookies of the jar.
+func (j *Jar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries, j.dirty = make(map[string]*Entry), true
+}
+
+// Load add the cookies read from r in the format, the entries are filtered as Add does.
+func (j *Jar) Load(r io.Reader, format Format) error {
+	var (
+		entries []Entry
+		err     error
+	)
+
+	switch format {
+	case FormatNetscape:
+		entries, err = readNetscape(r)
+	default:
+		entries, err = readJSON(r)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.addLocked(entries, time.Now())
+
+	return nil
+}
+
+// LoadFile add the cookies read from the file in the format.
+func (j *Jar) LoadFile(filename string, format Format) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return j.Load(f, formatOf(filename, format))
+}
+
+// Save write the cookies which are not expired to w in the format,
+// session cookies are only written if Options.PersistSessionCookies is enabled.
+func (j *Jar) Save(w io.Writer, format Format) error {
+	j.mu.Lock()
+	entries := j.entriesLocked(time.Now(), j.opts.PersistSessionCookies)
+	j.mu.Unlock()
+
+	if format == FormatNetscape {
+		return writeNetscape(w, entries)
+	}
+
+	return writeJSON(w, entries)
+}
+
+// SaveFile write the cookies to the file in the format, the file is replaced atomically.
+// The jar stays changed if the write fails, so Flush, the autosave and Close retry it.
+func (j *Jar) SaveFile(filename string, format Format) (err error) {
+	// the flag is cleared before the cookies are read, so a change made during the write is saved next time
+	j.mu.Lock()
+	j.dirty = false
+	j.mu.Unlock()
+
+	defer func() {
+		if err != nil {
+			j.mu.Lock()
+			j.dirty = true
+			j.mu.Unlock()
+		}
+	}()
+
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err = f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err = j.Save(f, formatOf(filename, format)); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filename)
+}
+
+// Flush save the jar to Options.Filename if it was changed since it was loaded or saved.
+func (j *Jar) Flush() error {
+	j.mu.Lock()
+	dirty := j.dirty
+	j.mu.Unlock()
+
+	if !dirty || j.opts.Filename == "" {
+		return nil
+	}
+
+	return j.SaveFile(j.opts.Filename, j.opts.Format)
+}
+
+// Close stop the autosave and save the jar to Options.Filename.
+func (j *Jar) Close() error {
+	j.closeOnce.Do(func() {
+		if j.stop != nil {
+			close(j.stop)
+			<-j.done
+		}
+	})
+
+	return j.Flush()
+}
+
+func (j *Jar) autosave(interval time.Duration) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = j.Flush()
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+func (j *Jar) entriesLocked(now time.Time, session bool) []Entry {
+	entries := make([]Entry, 0, len(j.entries))
+	for _, e := range j.entries {
+		if !e.expired(now) && (session || e.Persistent) {
+			entries = append(entries, *e)
+		}
+	}
+
+	sort.Slice(entries, func(i, k int) bool {
+		return entries[i].seq < entries[k].seq
+	})
+
+	return entries
+}
+
+func (j *Jar) addLocked(entries []Entry, now time.Time) {
+	for i := range entries {
+		e := entries[i]
+		if e.Name == "" || e.Domain == "" || e.expired(now) {
+			continue
+		}
+
+		e.Domain = strings.ToLower(strings.TrimPrefix(e.Domain, "."))
+		if !e.HostOnly && !isIP(e.Domain) && j.psl.PublicSuffix(e.Domain) == e.Domain {
+			continue
+		}
+
+		if e.Path == "" || e.Path[0] != '/' {
+			e.Path = "/"
+		}
+		if e.Creation.IsZero() {
+			e.Creation = now
+		}
+
+		if old, ok := j.entries[e.id()]; ok {
+			e.seq = old.seq
+		} else {
+			e.seq = j.seq
+			j.seq++
+		}
+
+		j.entries[e.id()], j.dirty = &e, true
+	}
+}
+
+// newEntry create an entry of a cookie set by the host, remove reports whether the cookie deletes a stored one.
+func (j *Jar) newEntry(c *http.Cookie, host, defPath string, secure bool, now time.Time) (e *Entry, remove bool, err error) {
+	if c.Name == "" {
+		return nil, false, errors.New("cookiejar: empty name")
+	}
+
+	if c.Secure && !secure {
+		return nil, false, errors.New("cookiejar: secure cookie from an insecure url")
+	}
+
+	e = &Entry{
+		Name:       c.Name,
+		Value:      c.Value,
+		Path:       c.Path,
+		Secure:     c.Secure,
+		HttpOnly:   c.HttpOnly,
+		SameSite:   c.SameSite,
+		Creation:   now,
+		LastAccess: now,
+	}
+
+	if e.Path == "" || e.Path[0] != '/' {
+		e.Path = defPath
+	}
+
+	if e.Domain, e.HostOnly, err = j.domainAndType(host, c.Domain); err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case c.MaxAge < 0:
+		return e, true, nil
+	case c.MaxAge > 0:
+		e.Expires, e.Persistent = now.Add(time.Duration(c.MaxAge)*time.Second), true
+	case !c.Expires.IsZero():
+		if !c.Expires.After(now) {
+			return e, true, nil
+		}
+		e.Expires, e.Persistent = c.Expires, true
+	}
+
+	return e, false, nil
+}
+
+// domainAndType determine the domain of a cookie set by the host and whether it's host only,
+// a cookie for a public suffix or a domain which doesn't match the host is rejected.
+func (j *Jar) domainAndType(host, domain string) (string, bool, error) {
+	if domain == "" {
+		return host, true, nil
+	}
+
+	if isIP(host) {
+		if host != domain {
+			return "", false, errors.New("cookiejar: domain cookie for an ip address")
+		}
+		return host, true, nil
+	}
+
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if domain == "" || domain[len(domain)-1] == '.' {
+		return "", false, errors.New("cookiejar: malformed domain")
+	}
+
+	if suffix := j.psl.PublicSuffix(domain); suffix != "" && suffix == domain {
+		if host == domain {
+			return host, true, nil
+		}
+		return "", false, fmt.Errorf("cookiejar: domain %q is a public suffix", domain)
+	}
+
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return "", false, fmt.Errorf("cookiejar: domain %q doesn't match host %q", domain, host)
+	}
+
+	return domain, false, nil
+}
+
+func (e *Entry) domainMatch(host string) bool {
+	if host == e.Domain {
+		return true
+	}
+
+	return !e.HostOnly && strings.HasSuffix(host, "."+e.Domain)
+}
+
+// pathMatch determine if the request path matches the path of a cookie by RFC 6265 section 5.1.4.
+func pathMatch(path, cookiePath string) bool {
+	if path == cookiePath {
+		return true
+	}
+
+	if !strings.HasPrefix(path, cookiePath) {
+		return false
+	}
+
+	return strings.HasSuffix(cookiePath, "/") || path[len(cookiePath)] == '/'
+}
+
+// defaultPath returns the default path of a cookie by RFC 6265 section 5.1.4.
+func defaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+
+	return path[:i]
+}
+
+// canonicalHost strips the port and the trailing dot of the host.
+func canonicalHost(host string) (string, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(strings.Trim(host, "[]"), "."))
+	if host == "" {
+		return "", errors.New("cookiejar: empty host")
+	}
+
+	return host, nil
+}
+
+func isIP(host string) bool {
+	return net.ParseIP(host) != nil
+}
+
+func formatOf(filename string, format Format) Format {
+	if format != FormatAuto {
+		return format
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".txt") {
+		return FormatNetscape
+	}
+
+	return FormatJSON
+}
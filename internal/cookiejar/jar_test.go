@@ -0,0 +1,155 @@
+package cookiejar_test
+
+import (
+	"github.com/dobyte/http/internal/cookiejar"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func cookieString(cookies []*http.Cookie) string {
+	pairs := make([]string, len(cookies))
+	for i, c := range cookies {
+		pairs[i] = c.Name + "=" + c.Value
+	}
+
+	return strings.Join(pairs, "; ")
+}
+
+func TestJar_SetCookies(t *testing.T) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse("https://www.example.co.uk/account/login")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "2", Domain: ".example.co.uk", Path: "/", MaxAge: 3600},
+		{Name: "super", Value: "3", Domain: "co.uk"},
+		{Name: "other", Value: "4", Domain: "example.com"},
+		{Name: "gone", Value: "5", Expires: time.Now().Add(-time.Hour)},
+	})
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://www.example.co.uk/account/profile", want: "host=1; domain=2"},
+		{url: "https://api.example.co.uk/account", want: "domain=2"},
+		{url: "https://www.example.co.uk/", want: "domain=2"},
+		{url: "https://www.other.co.uk/account", want: ""},
+	}
+
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := cookieString(jar.Cookies(u)); got != tt.want {
+			t.Errorf("Cookies(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	jar.SetCookies(u, []*http.Cookie{{Name: "domain", Domain: "example.co.uk", Path: "/", MaxAge: -1}})
+	if got := cookieString(jar.Cookies(u)); got != "host=1" {
+		t.Errorf("Cookies() after delete = %q", got)
+	}
+}
+
+func TestJar_SaveFile(t *testing.T) {
+	for _, name := range []string{"cookies.json", "cookies.txt"} {
+		filename := filepath.Join(t.TempDir(), name)
+
+		jar, err := cookiejar.New(&cookiejar.Options{Filename: filename})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		u, _ := url.Parse("https://example.com/")
+		jar.SetCookies(u, []*http.Cookie{
+			{Name: "sid", Value: "abc", MaxAge: 3600, HttpOnly: true, Secure: true},
+			{Name: "pref", Value: "dark", Domain: "example.com", MaxAge: 3600},
+			{Name: "session", Value: "1"},
+		})
+
+		if err = jar.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := cookiejar.New(&cookiejar.Options{Filename: filename})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := cookieString(loaded.Cookies(u)); got != "sid=abc; pref=dark" {
+			t.Errorf("%s: Cookies() = %q", name, got)
+		}
+
+		sub, _ := url.Parse("https://www.example.com/")
+		if got := cookieString(loaded.Cookies(sub)); got != "pref=dark" {
+			t.Errorf("%s: Cookies(subdomain) = %q", name, got)
+		}
+
+		if entries := loaded.Entries(); len(entries) != 2 || !entries[0].HttpOnly || !entries[0].Secure {
+			t.Errorf("%s: Entries() = %+v", name, entries)
+		}
+	}
+}
+
+func TestJar_Flush(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	filename := filepath.Join(dir, "cookies.json")
+
+	jar, err := cookiejar.New(&cookiejar.Options{Filename: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse("https://example.com/")
+	jar.SetCookies(u, []*http.Cookie{{Name: "sid", Value: "abc", MaxAge: 3600}})
+
+	if err = jar.Flush(); err == nil {
+		t.Fatal("Flush() into a missing directory err = nil")
+	}
+
+	if err = os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = jar.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(filename); err != nil {
+		t.Errorf("Flush() after a failed write didn't retry: %v", err)
+	}
+}
+
+func TestJar_Load(t *testing.T) {
+	const cookies = `# Netscape HTTP Cookie File
+.example.com	TRUE	/	FALSE	4102444800	theme	light
+#HttpOnly_example.com	FALSE	/api	TRUE	0	token	xyz
+.co.uk	TRUE	/	FALSE	4102444800	super	1
+example.com	FALSE	/	FALSE	946684800	old	1
+`
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = jar.Load(strings.NewReader(cookies), cookiejar.FormatNetscape); err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse("https://example.com/api/v1")
+	if got := cookieString(jar.Cookies(u)); got != "token=xyz; theme=light" {
+		t.Errorf("Cookies() = %q", got)
+	}
+
+	if err = jar.Load(strings.NewReader("example.com\tFALSE\t/"), cookiejar.FormatNetscape); err == nil {
+		t.Errorf("Load() with malformed line err = nil")
+	}
+}
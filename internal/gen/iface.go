@@ -0,0 +1,535 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	libraryPath = "github.com/dobyte/http"
+	generator   = "dobyte-http-gen"
+)
+
+var (
+	annotationRegexp  = regexp.MustCompile(`^@(\w+)\s*(.*)$`)
+	placeholderRegexp = regexp.MustCompile(`\{(\w+)(?:\.\.\.)?\}`)
+	httpMethods       = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true}
+)
+
+type Interface struct {
+	Name    string
+	Methods []*Method
+}
+
+type Method struct {
+	Name       string
+	Doc        []string
+	Params     []*Param
+	Results    []string
+	HttpMethod string
+	Path       string
+	Headers    [][2]string // header names and go expressions of the values
+	Timeout    time.Duration
+	ErrorType  string
+	Context    string // the name of the context param
+	Body       string // the name of the body param
+	PathParams []*Param
+	Query      []*Param
+}
+
+type Param struct {
+	Name     string
+	Type     string
+	Variadic bool
+}
+
+type interfaceFile struct {
+	pkg        string
+	lib        string
+	imports    map[string]string // package name to import path
+	used       map[string]bool
+	interfaces []*Interface
+}
+
+// GenerateInterfaces parse the annotated interfaces in the source and generate their implementations.
+//
+// Each method is annotated in its doc comment:
+//
+//	// @GET /users/{id}
+//	// @Header X-Tenant: {tenant}
+//	// @Timeout 5s
+//	// @Error APIError
+//	// @Body user
+//	GetUser(ctx context.Context, tenant string, id int, page int) (*User, error)
+//
+// A context.Context param is the context of the request, params named in the path are path params,
+// params named in {} of a header are header values, the param named by @Body or named body is the body,
+// and the rest are query params. The results are (T, error), (*http.Response, error) or error.
+func GenerateInterfaces(filename string, src []byte, names []string) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &interfaceFile{
+		pkg:     file.Name.Name,
+		imports: make(map[string]string),
+		used:    make(map[string]bool),
+	}
+
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := importName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		if path == libraryPath {
+			f.lib = name
+		}
+		f.imports[name] = path
+	}
+
+	if f.lib == "" {
+		f.lib = "http"
+		if _, ok := f.imports[f.lib]; ok {
+			f.lib = "dhttp"
+		}
+		f.imports[f.lib] = libraryPath
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			it, ok := ts.Type.(*ast.InterfaceType)
+			if !ok || !wanted[ts.Name.Name] {
+				continue
+			}
+
+			iface, err := f.parseInterface(ts.Name.Name, it)
+			if err != nil {
+				return nil, err
+			}
+
+			f.interfaces = append(f.interfaces, iface)
+			delete(wanted, ts.Name.Name)
+		}
+	}
+
+	if len(wanted) > 0 {
+		missing := make([]string, 0, len(wanted))
+		for name := range wanted {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+
+		return nil, fmt.Errorf("%s: interface %s not found", generator, strings.Join(missing, ", "))
+	}
+
+	return f.render()
+}
+
+func (f *interfaceFile) parseInterface(name string, it *ast.InterfaceType) (*Interface, error) {
+	iface := &Interface{Name: name}
+
+	for _, field := range it.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s: %s: embedded interfaces are not supported", generator, name)
+		}
+
+		m, err := f.parseMethod(field.Names[0].Name, ft, field.Doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s.%s: %w", generator, name, field.Names[0].Name, err)
+		}
+
+		iface.Methods = append(iface.Methods, m)
+	}
+
+	return iface, nil
+}
+
+func (f *interfaceFile) parseMethod(name string, ft *ast.FuncType, doc *ast.CommentGroup) (*Method, error) {
+	m := &Method{Name: name}
+
+	if err := m.parseAnnotations(doc); err != nil {
+		return nil, err
+	}
+
+	n := 0
+	for _, field := range ft.Params.List {
+		typ, variadic := field.Type, false
+		if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+			typ, variadic = ellipsis.Elt, true
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent("p" + strconv.Itoa(n))}
+		}
+
+		for _, ident := range names {
+			m.Params = append(m.Params, &Param{Name: ident.Name, Type: f.typeString(typ), Variadic: variadic})
+			n++
+		}
+	}
+
+	if ft.Results != nil {
+		for _, field := range ft.Results.List {
+			count := len(field.Names)
+			if count == 0 {
+				count = 1
+			}
+
+			for i := 0; i < count; i++ {
+				m.Results = append(m.Results, f.typeString(field.Type))
+			}
+		}
+	}
+
+	switch {
+	case len(m.Results) == 1 && m.Results[0] == "error":
+	case len(m.Results) == 2 && m.Results[1] == "error":
+	default:
+		return nil, fmt.Errorf("results must be (T, error) or error")
+	}
+
+	return m, m.classifyParams()
+}
+
+// parseAnnotations parse the annotations in the doc comment of a method.
+func (m *Method) parseAnnotations(doc *ast.CommentGroup) error {
+	if doc == nil {
+		return fmt.Errorf("missing annotation, eg: @GET /users/{id}")
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(doc.Text()), "\n") {
+		matches := annotationRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			m.Doc = append(m.Doc, line)
+			continue
+		}
+
+		key, value := strings.ToUpper(matches[1]), strings.TrimSpace(matches[2])
+
+		switch {
+		case httpMethods[key]:
+			m.HttpMethod, m.Path = key, value
+		case key == "HEADER":
+			k, v, ok := strings.Cut(value, ":")
+			if !ok {
+				return fmt.Errorf("invalid header annotation %q", value)
+			}
+			m.Headers = append(m.Headers, [2]string{strings.TrimSpace(k), strings.TrimSpace(v)})
+		case key == "TIMEOUT":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid timeout annotation %q", value)
+			}
+			m.Timeout = timeout
+		case key == "ERROR":
+			m.ErrorType = value
+		case key == "BODY":
+			m.Body = value
+		default:
+			return fmt.Errorf("unknown annotation @%s", matches[1])
+		}
+	}
+
+	if m.HttpMethod == "" {
+		return fmt.Errorf("missing annotation, eg: @GET /users/{id}")
+	}
+
+	if m.Body != "" && !m.hasHeader("Content-Type") {
+		m.Headers = append(m.Headers, [2]string{"Content-Type", "application/json"})
+	}
+
+	return nil
+}
+
+// hasHeader determine if the header is annotated.
+func (m *Method) hasHeader(name string) bool {
+	for _, header := range m.Headers {
+		if strings.EqualFold(header[0], name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classifyParams sort the params into context, path params, header values, body and query params.
+func (m *Method) classifyParams() error {
+	inPath := make(map[string]bool)
+	for _, matches := range placeholderRegexp.FindAllStringSubmatch(m.Path, -1) {
+		inPath[matches[1]] = true
+	}
+
+	inHeader := make(map[string]bool)
+	for _, header := range m.Headers {
+		for _, matches := range placeholderRegexp.FindAllStringSubmatch(header[1], -1) {
+			inHeader[matches[1]] = true
+		}
+	}
+
+	if m.Body == "" {
+		for _, p := range m.Params {
+			if p.Name == "body" {
+				m.Body = p.Name
+			}
+		}
+
+		if m.Body != "" && !m.hasHeader("Content-Type") {
+			m.Headers = append(m.Headers, [2]string{"Content-Type", "application/json"})
+		}
+	}
+
+	found := make(map[string]bool)
+	for _, p := range m.Params {
+		found[p.Name] = true
+
+		switch {
+		case p.Type == "context.Context":
+			m.Context = p.Name
+		case inPath[p.Name]:
+			m.PathParams = append(m.PathParams, p)
+		case inHeader[p.Name], p.Name == m.Body:
+		default:
+			m.Query = append(m.Query, p)
+		}
+	}
+
+	for _, names := range []map[string]bool{inPath, inHeader, {m.Body: m.Body != ""}} {
+		for name, ok := range names {
+			if ok && !found[name] {
+				return fmt.Errorf("param %q not found", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// typeString print a type expression and record the packages it uses.
+func (f *interfaceFile) typeString(expr ast.Expr) string {
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				f.used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	return types.ExprString(expr)
+}
+
+func (f *interfaceFile) render() ([]byte, error) {
+	var (
+		buf     bytes.Buffer
+		imports = map[string]bool{f.lib: true}
+	)
+
+	for name := range f.used {
+		imports[name] = true
+	}
+
+	for _, iface := range f.interfaces {
+		for _, m := range iface.Methods {
+			if m.Context == "" || m.Timeout > 0 {
+				imports["context"] = true
+			}
+			if m.Timeout > 0 {
+				imports["time"] = true
+			}
+			for _, header := range m.Headers {
+				if placeholderRegexp.MatchString(header[1]) {
+					imports["fmt"] = true
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(&buf, "// Code generated by %s. DO NOT EDIT.\n\npackage %s\n\nimport (\n", generator, f.pkg)
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path, ok := f.imports[name]
+		if !ok {
+			path = name
+		}
+
+		if importName(path) == name {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		} else {
+			fmt.Fprintf(&buf, "\t%s %q\n", name, path)
+		}
+	}
+
+	buf.WriteString(")\n")
+
+	for _, iface := range f.interfaces {
+		f.renderInterface(&buf, iface)
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: format generated code: %w\n%s", generator, err, buf.String())
+	}
+
+	return out, nil
+}
+
+func (f *interfaceFile) renderInterface(buf *bytes.Buffer, iface *Interface) {
+	impl := iface.Name + "Client"
+
+	fmt.Fprintf(buf, "\n// %s implements %s with a %s.Client.\n", impl, iface.Name, f.lib)
+	fmt.Fprintf(buf, "type %s struct {\n\tclient *%s.Client\n}\n\n", impl, f.lib)
+	fmt.Fprintf(buf, "var _ %s = (*%s)(nil)\n\n", iface.Name, impl)
+	fmt.Fprintf(buf, "// New%s create a %s with the client.\n", impl, impl)
+	fmt.Fprintf(buf, "func New%s(client *%s.Client) *%s {\n\treturn &%s{client: client}\n}\n", impl, f.lib, impl, impl)
+
+	for _, m := range iface.Methods {
+		f.renderMethod(buf, impl, m)
+	}
+}
+
+func (f *interfaceFile) renderMethod(buf *bytes.Buffer, impl string, m *Method) {
+	params := make([]string, 0, len(m.Params))
+	for _, p := range m.Params {
+		if p.Variadic {
+			params = append(params, p.Name+" ..."+p.Type)
+		} else {
+			params = append(params, p.Name+" "+p.Type)
+		}
+	}
+
+	results := strings.Join(m.Results, ", ")
+	if len(m.Results) > 1 {
+		results = "(" + results + ")"
+	}
+
+	buf.WriteString("\n")
+	for _, line := range m.Doc {
+		fmt.Fprintf(buf, "// %s\n", line)
+	}
+
+	fmt.Fprintf(buf, "func (c *%s) %s(%s) %s {\n", impl, m.Name, strings.Join(params, ", "), results)
+
+	ctx := m.Context
+	if ctx == "" {
+		ctx = "ctx"
+		buf.WriteString("\tctx := context.Background()\n\n")
+	}
+
+	if m.Timeout > 0 {
+		fmt.Fprintf(buf, "\t%s, cancel := context.WithTimeout(%s, %d*time.Millisecond)\n\tdefer cancel()\n\n", ctx, ctx, m.Timeout.Milliseconds())
+	}
+
+	if m.ErrorType != "" {
+		fmt.Fprintf(buf, "\tvar apiErr %s\n\n", m.ErrorType)
+	}
+
+	fmt.Fprintf(buf, "\topts := &%s.RequestOptions{\n\t\tContext: %s,\n\t\tStatusError: true,\n", f.lib, ctx)
+
+	if m.ErrorType != "" {
+		buf.WriteString("\t\tError: &apiErr,\n")
+	}
+
+	if len(m.PathParams) > 0 {
+		buf.WriteString("\t\tPathParams: map[string]interface{}{\n")
+		for _, p := range m.PathParams {
+			fmt.Fprintf(buf, "\t\t\t%q: %s,\n", p.Name, p.Name)
+		}
+		buf.WriteString("\t\t},\n")
+	}
+
+	if len(m.Query) > 0 {
+		buf.WriteString("\t\tQuery: map[string]interface{}{\n")
+		for _, p := range m.Query {
+			fmt.Fprintf(buf, "\t\t\t%q: %s,\n", p.Name, p.Name)
+		}
+		buf.WriteString("\t\t},\n")
+	}
+
+	if len(m.Headers) > 0 {
+		buf.WriteString("\t\tHeaders: map[string]string{\n")
+		for _, header := range m.Headers {
+			fmt.Fprintf(buf, "\t\t\t%q: %s,\n", header[0], headerValue(header[1]))
+		}
+		buf.WriteString("\t\t},\n")
+	}
+
+	buf.WriteString("\t}\n\n")
+
+	body := "nil"
+	if m.Body != "" {
+		body = m.Body
+	}
+
+	switch {
+	case len(m.Results) == 1:
+		fmt.Fprintf(buf, "\t_, err := c.client.Request(%q, %q, %s, opts)\n\n\treturn err\n}\n", m.HttpMethod, m.Path, body)
+	case m.Results[0] == "*"+f.lib+".Response":
+		fmt.Fprintf(buf, "\treturn c.client.Request(%q, %q, %s, opts)\n}\n", m.HttpMethod, m.Path, body)
+	default:
+		fmt.Fprintf(buf, "\tresult, _, err := %s.RequestAs[%s](%s, c.client, %q, %q, %s, opts)\n\n\treturn result, err\n}\n", f.lib, m.Results[0], ctx, m.HttpMethod, m.Path, body)
+	}
+}
+
+// headerValue convert a header annotation into a go expression, {name} is replaced by the param.
+func headerValue(value string) string {
+	if !placeholderRegexp.MatchString(value) {
+		return strconv.Quote(value)
+	}
+
+	args := make([]string, 0)
+	format := placeholderRegexp.ReplaceAllStringFunc(strings.ReplaceAll(value, "%", "%%"), func(s string) string {
+		args = append(args, placeholderRegexp.FindStringSubmatch(s)[1])
+		return "%v"
+	})
+
+	return fmt.Sprintf("fmt.Sprintf(%q, %s)", format, strings.Join(args, ", "))
+}
+
+// importName guess the package name of an import path, eg: gopkg.in/yaml.v3 is yaml.
+func importName(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" && strings.Contains(path, "/") {
+		return importName(path[:strings.LastIndex(path, "/")])
+	}
+
+	return strings.TrimPrefix(strings.ReplaceAll(name, "-", ""), "go")
+}
@@ -0,0 +1,32 @@
+package gen_test
+
+import (
+	"github.com/dobyte/http/internal/gen"
+	"os"
+	"testing"
+)
+
+func TestGenerateInterfaces(t *testing.T) {
+	src, err := os.ReadFile("testdata/api.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gen.GenerateInterfaces("api.go", src, []string{"UserAPI"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile("testdata/api_http.go.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("GenerateInterfaces() got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err = gen.GenerateInterfaces("api.go", src, []string{"OrderAPI"}); err == nil {
+		t.Errorf("GenerateInterfaces() with missing interface err = nil")
+	}
+}
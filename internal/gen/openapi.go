@@ -0,0 +1,253 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// Document is the subset of an OpenAPI 3.0/3.1 document used to generate clients.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas"`
+	Parameters    map[string]*Parameter   `json:"parameters"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies"`
+	Responses     map[string]*Response    `json:"responses"`
+}
+
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Put        *Operation   `json:"put"`
+	Post       *Operation   `json:"post"`
+	Delete     *Operation   `json:"delete"`
+	Options    *Operation   `json:"options"`
+	Head       *Operation   `json:"head"`
+	Patch      *Operation   `json:"patch"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Deprecated  bool                 `json:"deprecated"`
+	Parameters  []*Parameter         `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Required    bool                  `json:"required"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 SchemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *Schema            `json:"items"`
+	AdditionalProperties *Schema            `json:"-"`
+	AllOf                []*Schema          `json:"allOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+}
+
+// SchemaType is the type of a schema, a string in 3.0 or a list of strings in 3.1.
+type SchemaType []string
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]string)(t))
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*t = SchemaType{s}
+
+	return nil
+}
+
+// Is determine if the schema type contains the type.
+func (t SchemaType) Is(typ string) bool {
+	for _, s := range t {
+		if s == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Primary returns the first type which is not null.
+func (t SchemaType) Primary() string {
+	for _, s := range t {
+		if s != "null" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type schema Schema
+
+	var raw struct {
+		schema
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*s = Schema(raw.schema)
+
+	if len(raw.AdditionalProperties) > 0 && raw.AdditionalProperties[0] == '{' {
+		s.AdditionalProperties = &Schema{}
+		if err := json.Unmarshal(raw.AdditionalProperties, s.AdditionalProperties); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseDocument parse an OpenAPI document in JSON or YAML.
+func ParseDocument(data []byte) (*Document, error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) > 0 && data[0] != '{' {
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("%s: parse yaml: %w", generator, err)
+		}
+
+		b, err := json.Marshal(normalizeYaml(v))
+		if err != nil {
+			return nil, fmt.Errorf("%s: parse yaml: %w", generator, err)
+		}
+		data = b
+	}
+
+	doc := &Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("%s: parse document: %w", generator, err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%s: unsupported openapi version %q, want 3.x", generator, doc.OpenAPI)
+	}
+
+	return doc, nil
+}
+
+// normalizeYaml convert the maps decoded from yaml into maps with string keys.
+func normalizeYaml(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			val[key] = normalizeYaml(item)
+		}
+		return val
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			m[fmt.Sprint(key)] = normalizeYaml(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range val {
+			val[i] = normalizeYaml(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// resolveParameter returns the parameter referenced by $ref.
+func (d *Document) resolveParameter(p *Parameter) (*Parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+
+	if r, ok := d.Components.Parameters[refName(p.Ref, "parameters")]; ok {
+		return d.resolveParameter(r)
+	}
+
+	return nil, fmt.Errorf("unresolved reference %q", p.Ref)
+}
+
+// resolveRequestBody returns the request body referenced by $ref.
+func (d *Document) resolveRequestBody(b *RequestBody) (*RequestBody, error) {
+	if b.Ref == "" {
+		return b, nil
+	}
+
+	if r, ok := d.Components.RequestBodies[refName(b.Ref, "requestBodies")]; ok {
+		return d.resolveRequestBody(r)
+	}
+
+	return nil, fmt.Errorf("unresolved reference %q", b.Ref)
+}
+
+// resolveResponse returns the response referenced by $ref.
+func (d *Document) resolveResponse(r *Response) (*Response, error) {
+	if r.Ref == "" {
+		return r, nil
+	}
+
+	if resp, ok := d.Components.Responses[refName(r.Ref, "responses")]; ok {
+		return d.resolveResponse(resp)
+	}
+
+	return nil, fmt.Errorf("unresolved reference %q", r.Ref)
+}
+
+// refName returns the name of a local reference, eg: #/components/schemas/Pet is Pet.
+func refName(ref, kind string) string {
+	return strings.TrimPrefix(ref, "#/components/"+kind+"/")
+}
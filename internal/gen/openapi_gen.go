@@ -0,0 +1,775 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	operationMethods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH"}
+	initialisms      = map[string]bool{"ID": true, "URL": true, "URI": true, "HTTP": true, "API": true, "JSON": true, "XML": true, "UUID": true, "IP": true, "SQL": true}
+)
+
+type openapiGenerator struct {
+	doc     *Document
+	pkg     string
+	imports map[string]bool
+	types   map[string]*Schema // the named types to generate
+	order   []string
+	enums   map[string]bool
+	buf     bytes.Buffer
+}
+
+type operation struct {
+	method     string
+	path       string
+	name       string
+	doc        []string
+	params     []*Parameter
+	body       *Schema
+	bodyType   string
+	multipart  bool
+	result     string
+	errorType  string
+	contentTyp string
+}
+
+// GenerateOpenAPI generate model structs and a typed client from an OpenAPI 3.0/3.1 document in JSON or YAML.
+//
+// Schemas of the components become structs, and each operation becomes a method of Client
+// named by its operationId. Path, query, header and cookie parameters are fields of a params struct,
+// json bodies are sent as they are, multipart/form-data bodies are sent by Client.Upload
+// with the binary properties as files, and the json schema of the error responses is decoded
+// into StatusError.Value.
+func GenerateOpenAPI(data []byte, pkg string) ([]byte, error) {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &openapiGenerator{
+		doc:     doc,
+		pkg:     pkg,
+		imports: map[string]bool{"context": true, libraryPath: true},
+		types:   make(map[string]*Schema),
+		enums:   make(map[string]bool),
+	}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		g.addType(goName(name), doc.Components.Schemas[name])
+	}
+
+	ops, err := g.operations()
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	g.renderClient(&body, ops)
+
+	models := g.buf
+	g.buf = bytes.Buffer{}
+
+	for i := 0; i < len(g.order); i++ {
+		g.renderType(g.order[i], g.types[g.order[i]])
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by %s. DO NOT EDIT.\n", generator)
+	if doc.Info.Title != "" {
+		fmt.Fprintf(&out, "// Source: %s %s\n", doc.Info.Title, doc.Info.Version)
+	}
+	fmt.Fprintf(&out, "\npackage %s\n\nimport (\n", pkg)
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(g.buf.Bytes())
+	out.Write(models.Bytes())
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: format generated code: %w\n%s", generator, err, out.String())
+	}
+
+	return src, nil
+}
+
+// addType register a named type, returns the unique name.
+func (g *openapiGenerator) addType(name string, schema *Schema) string {
+	unique := name
+	for i := 2; g.types[unique] != nil; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+
+	g.types[unique] = schema
+	g.order = append(g.order, unique)
+
+	return unique
+}
+
+// goType returns the go type of a schema, inline objects are named by the hint.
+func (g *openapiGenerator) goType(schema *Schema, hint string) string {
+	if schema == nil {
+		return "interface{}"
+	}
+
+	if schema.Ref != "" {
+		return goName(refName(schema.Ref, "schemas"))
+	}
+
+	if len(schema.AllOf) == 1 && len(schema.Properties) == 0 {
+		return g.goType(schema.AllOf[0], hint)
+	}
+
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return "interface{}"
+	}
+
+	switch schema.Type.Primary() {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			g.imports["time"] = true
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(schema.Items, hint+"Item")
+	case "object", "":
+		if len(schema.Properties) > 0 || len(schema.AllOf) > 0 {
+			return g.addType(hint, schema)
+		}
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + g.goType(schema.AdditionalProperties, hint+"Value")
+		}
+		return "map[string]interface{}"
+	}
+
+	return "interface{}"
+}
+
+// renderType render a named type of the components or an inline object.
+func (g *openapiGenerator) renderType(name string, schema *Schema) {
+	g.buf.WriteString("\n")
+	writeDoc(&g.buf, name, schema.Description)
+
+	if schema.Ref != "" || !isObject(schema) {
+		typ := g.goType(schema, name+"Item")
+		if len(schema.Enum) > 0 && typ == "string" {
+			fmt.Fprintf(&g.buf, "type %s string\n", name)
+			g.renderEnum(name, schema)
+			return
+		}
+
+		fmt.Fprintf(&g.buf, "type %s = %s\n", name, typ)
+		return
+	}
+
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+
+	for _, part := range schema.AllOf {
+		if part.Ref != "" {
+			fmt.Fprintf(&g.buf, "\t%s\n", goName(refName(part.Ref, "schemas")))
+		}
+	}
+
+	for _, part := range append([]*Schema{schema}, schema.AllOf...) {
+		if part.Ref != "" {
+			continue
+		}
+
+		required := make(map[string]bool, len(part.Required))
+		for _, field := range part.Required {
+			required[field] = true
+		}
+
+		props := make([]string, 0, len(part.Properties))
+		for prop := range part.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+
+		for _, prop := range props {
+			var (
+				field  = goName(prop)
+				ps     = part.Properties[prop]
+				typ    = g.goType(ps, name+field)
+				tag    = prop
+				isRef  = ps.Ref != "" && g.isStruct(typ)
+				isNull = ps.Nullable || ps.Type.Is("null")
+			)
+
+			if !required[prop] {
+				tag += ",omitempty"
+			}
+
+			if isRef || g.isStruct(typ) && !required[prop] || (isNull || !required[prop] && typ == "time.Time") && isScalar(typ) {
+				typ = "*" + typ
+			}
+
+			if ps.Description != "" {
+				for _, line := range strings.Split(strings.TrimSpace(ps.Description), "\n") {
+					fmt.Fprintf(&g.buf, "\t// %s\n", line)
+				}
+			}
+			fmt.Fprintf(&g.buf, "\t%s %s `json:%q`\n", field, typ, tag)
+		}
+	}
+
+	g.buf.WriteString("}\n")
+}
+
+// renderEnum render the constants of a string enum.
+func (g *openapiGenerator) renderEnum(name string, schema *Schema) {
+	g.buf.WriteString("\nconst (\n")
+
+	for _, value := range schema.Enum {
+		s := fmt.Sprint(value)
+		fmt.Fprintf(&g.buf, "\t%s%s %s = %q\n", name, goName(s), name, s)
+	}
+
+	g.buf.WriteString(")\n")
+}
+
+// isStruct determine if the named type is generated as a struct.
+func (g *openapiGenerator) isStruct(name string) bool {
+	schema, ok := g.types[name]
+	return ok && schema.Ref == "" && isObject(schema)
+}
+
+// isScalar determine if the type is neither a slice, a map nor an interface.
+func isScalar(typ string) bool {
+	return !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "interface{}"
+}
+
+func isObject(schema *Schema) bool {
+	return (schema.Type.Primary() == "object" || schema.Type.Primary() == "") && (len(schema.Properties) > 0 || len(schema.AllOf) > 0 && len(schema.OneOf) == 0)
+}
+
+// operations collect the operations of the document sorted by path and method.
+func (g *openapiGenerator) operations() ([]*operation, error) {
+	paths := make([]string, 0, len(g.doc.Paths))
+	for path := range g.doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ops := make([]*operation, 0)
+	used := make(map[string]bool)
+
+	for _, path := range paths {
+		item := g.doc.Paths[path]
+
+		for _, method := range operationMethods {
+			op := item.operation(method)
+			if op == nil {
+				continue
+			}
+
+			o, err := g.operation(method, path, item, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s %s: %w", generator, method, path, err)
+			}
+
+			if used[o.name] {
+				return nil, fmt.Errorf("%s: %s %s: duplicate operation name %s", generator, method, path, o.name)
+			}
+			used[o.name] = true
+
+			ops = append(ops, o)
+		}
+	}
+
+	return ops, nil
+}
+
+func (item *PathItem) operation(method string) *Operation {
+	switch method {
+	case "GET":
+		return item.Get
+	case "PUT":
+		return item.Put
+	case "POST":
+		return item.Post
+	case "DELETE":
+		return item.Delete
+	case "OPTIONS":
+		return item.Options
+	case "HEAD":
+		return item.Head
+	case "PATCH":
+		return item.Patch
+	}
+
+	return nil
+}
+
+func (g *openapiGenerator) operation(method, path string, item *PathItem, op *Operation) (*operation, error) {
+	o := &operation{method: method, path: path, name: goName(op.OperationID)}
+	if o.name == "" {
+		o.name = goName(strings.ToLower(method) + " " + placeholderRegexp.ReplaceAllString(path, "by $1"))
+	}
+
+	for _, text := range []string{op.Summary, op.Description} {
+		if text = strings.TrimSpace(text); text != "" {
+			o.doc = append(o.doc, strings.Split(text, "\n")...)
+		}
+	}
+
+	if len(o.doc) == 0 {
+		o.doc = append(o.doc, fmt.Sprintf("Calls %s %s.", method, path))
+	}
+
+	if op.Deprecated {
+		o.doc = append(o.doc, "", "Deprecated: the operation is deprecated by the api.")
+	}
+
+	seen := make(map[string]int)
+	for _, p := range append(append([]*Parameter{}, item.Parameters...), op.Parameters...) {
+		resolved, err := g.doc.resolveParameter(p)
+		if err != nil {
+			return nil, err
+		}
+
+		key := resolved.In + ":" + resolved.Name
+		if i, ok := seen[key]; ok {
+			o.params[i] = resolved
+			continue
+		}
+
+		seen[key] = len(o.params)
+		o.params = append(o.params, resolved)
+	}
+
+	if op.RequestBody != nil {
+		body, err := g.doc.resolveRequestBody(op.RequestBody)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = o.setBody(g, body); err != nil {
+			return nil, err
+		}
+	}
+
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		resp, err := g.doc.resolveResponse(op.Responses[code])
+		if err != nil {
+			return nil, err
+		}
+
+		schema := jsonSchema(resp.Content)
+		if schema == nil {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(code, "2") && o.result == "":
+			o.result = g.goType(schema, o.name+"Response")
+		case !strings.HasPrefix(code, "2") && o.errorType == "":
+			o.errorType = g.goType(schema, o.name+"Error")
+		}
+	}
+
+	return o, nil
+}
+
+// setBody set the request body of the operation, json and multipart/form-data bodies are supported.
+func (o *operation) setBody(g *openapiGenerator, body *RequestBody) error {
+	if schema := jsonSchema(body.Content); schema != nil {
+		o.body, o.bodyType, o.contentTyp = schema, g.goType(schema, o.name+"Request"), "application/json"
+		return nil
+	}
+
+	if mt, ok := body.Content["multipart/form-data"]; ok && mt.Schema != nil {
+		schema := mt.Schema
+		if schema.Ref != "" {
+			schema = g.doc.Components.Schemas[refName(schema.Ref, "schemas")]
+		}
+
+		if schema == nil {
+			return fmt.Errorf("unresolved reference %q", mt.Schema.Ref)
+		}
+
+		form := &Schema{Type: SchemaType{"object"}, Properties: make(map[string]*Schema), Required: schema.Required}
+		for prop, ps := range schema.Properties {
+			if !isBinary(ps) {
+				form.Properties[prop] = ps
+			}
+		}
+
+		if o.method != "POST" {
+			return fmt.Errorf("multipart/form-data body is only supported by POST")
+		}
+
+		o.body, o.multipart = schema, true
+		if len(form.Properties) > 0 {
+			o.bodyType = g.addType(o.name+"Form", form)
+		}
+
+		return nil
+	}
+
+	if mt, ok := body.Content["application/x-www-form-urlencoded"]; ok && mt.Schema != nil {
+		o.body, o.bodyType, o.contentTyp = mt.Schema, g.goType(mt.Schema, o.name+"Request"), "application/x-www-form-urlencoded"
+		return nil
+	}
+
+	return fmt.Errorf("unsupported request body content type, want json, multipart/form-data or x-www-form-urlencoded")
+}
+
+// jsonSchema returns the schema of the json content.
+func jsonSchema(content map[string]*MediaType) *Schema {
+	types := make([]string, 0, len(content))
+	for typ := range content {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+
+	for _, typ := range types {
+		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(typ, ";")[0]))
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return content[typ].Schema
+		}
+	}
+
+	return nil
+}
+
+func isBinary(schema *Schema) bool {
+	if schema.Type.Primary() == "array" && schema.Items != nil {
+		return isBinary(schema.Items)
+	}
+
+	return schema.Type.Primary() == "string" && (schema.Format == "binary" || schema.Format == "base64")
+}
+
+// renderClient render the client and the params structs of the operations.
+func (g *openapiGenerator) renderClient(buf *bytes.Buffer, ops []*operation) {
+	buf.WriteString("\n// Client calls the operations of the api with a http.Client.\n")
+	buf.WriteString("type Client struct {\n\tclient *http.Client\n}\n\n")
+	buf.WriteString("// NewClient create a Client with the client.\n")
+	buf.WriteString("func NewClient(client *http.Client) *Client {\n\treturn &Client{client: client}\n}\n")
+
+	for _, o := range ops {
+		if len(o.params) > 0 {
+			g.renderParams(buf, o)
+		}
+		g.renderOperation(buf, o)
+	}
+}
+
+// renderParams render the params struct of an operation.
+func (g *openapiGenerator) renderParams(buf *bytes.Buffer, o *operation) {
+	fmt.Fprintf(buf, "\n// %sParams is the parameters of %s.\ntype %sParams struct {\n", o.name, o.name, o.name)
+
+	for _, p := range o.params {
+		typ := g.goType(p.Schema, o.name+goName(p.Name))
+		if !p.Required && isScalar(typ) {
+			typ = "*" + typ
+		}
+
+		var tag string
+		switch p.In {
+		case "path":
+			tag = fmt.Sprintf(`path:%q url:"-"`, p.Name)
+		case "query":
+			if p.Required {
+				tag = fmt.Sprintf(`url:%q`, p.Name)
+			} else {
+				tag = fmt.Sprintf(`url:"%s,omitempty"`, p.Name)
+			}
+		case "header":
+			tag = fmt.Sprintf(`header:%q url:"-"`, p.Name)
+		case "cookie":
+			tag = fmt.Sprintf(`cookie:%q url:"-"`, p.Name)
+		}
+
+		if p.Description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(p.Description), "\n") {
+				fmt.Fprintf(buf, "\t// %s\n", line)
+			}
+		}
+		fmt.Fprintf(buf, "\t%s %s `%s`\n", paramField(p), typ, tag)
+	}
+
+	buf.WriteString("}\n")
+}
+
+// renderOperation render the method of an operation.
+func (g *openapiGenerator) renderOperation(buf *bytes.Buffer, o *operation) {
+	args := []string{"ctx context.Context"}
+	if len(o.params) > 0 {
+		args = append(args, fmt.Sprintf("params *%sParams", o.name))
+	}
+
+	if o.multipart {
+		args = append(args, "files map[string][]string")
+	}
+
+	if o.bodyType != "" {
+		args = append(args, "body "+g.refType(o.bodyType))
+	}
+
+	results := "error"
+	if o.result != "" {
+		results = fmt.Sprintf("(%s, error)", g.refType(o.result))
+	}
+
+	buf.WriteString("\n")
+	writeDoc(buf, o.name, strings.Join(o.doc, "\n"))
+	fmt.Fprintf(buf, "func (c *Client) %s(%s) %s {\n", o.name, strings.Join(args, ", "), results)
+
+	if o.multipart {
+		g.renderUpload(buf, o)
+	} else {
+		g.renderRequest(buf, o)
+	}
+
+	buf.WriteString("}\n")
+}
+
+func (g *openapiGenerator) renderRequest(buf *bytes.Buffer, o *operation) {
+	buf.WriteString("\topts := &http.RequestOptions{\n\t\tContext: ctx,\n\t\tStatusError: true,\n")
+	if o.errorType != "" {
+		fmt.Fprintf(buf, "\t\tError: new(%s),\n", o.errorType)
+	}
+	if o.contentTyp != "" {
+		fmt.Fprintf(buf, "\t\tHeaders: map[string]string{http.HeaderContentType: %q},\n", o.contentTyp)
+	} else {
+		buf.WriteString("\t\tHeaders: map[string]string{},\n")
+	}
+	g.renderCookies(buf, o)
+	buf.WriteString("\t}\n\n")
+
+	g.renderParamOptions(buf, o, "opts")
+
+	body := "nil"
+	if o.bodyType != "" {
+		body = "body"
+	}
+
+	if o.result == "" {
+		fmt.Fprintf(buf, "\tresp, err := c.client.Request(%q, %q, %s, opts)\n", o.method, o.path, body)
+		buf.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n\n\treturn resp.Close()\n")
+		return
+	}
+
+	fmt.Fprintf(buf, "\tresult, _, err := http.RequestAs[%s](ctx, c.client, %q, %q, %s, opts)\n\n\treturn result, err\n", g.refType(o.result), o.method, o.path, body)
+}
+
+func (g *openapiGenerator) renderUpload(buf *bytes.Buffer, o *operation) {
+	buf.WriteString("\topts := &http.UploadOptions{\n\t\tContext: ctx,\n\t\tStatusError: true,\n")
+	if o.errorType != "" {
+		fmt.Fprintf(buf, "\t\tError: new(%s),\n", o.errorType)
+	}
+	buf.WriteString("\t\tHeaders: map[string]string{},\n")
+	g.renderCookies(buf, o)
+	buf.WriteString("\t}\n\n")
+
+	g.renderParamOptions(buf, o, "opts")
+
+	data := "nil"
+	if o.bodyType != "" {
+		data = "body"
+	}
+
+	fmt.Fprintf(buf, "\tresp, err := c.client.Upload(%q, files, %s, opts)\n", o.path, data)
+
+	if o.result == "" {
+		buf.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n\n\treturn resp.Close()\n")
+		return
+	}
+
+	result := o.result
+	if g.isStruct(result) {
+		fmt.Fprintf(buf, "\tif err != nil {\n\t\treturn nil, err\n\t}\n\n\tresult := &%s{}\n\n\treturn result, resp.ScanBody(result)\n", result)
+		return
+	}
+
+	fmt.Fprintf(buf, "\tvar result %s\n\tif err != nil {\n\t\treturn result, err\n\t}\n\n\treturn result, resp.ScanBody(&result)\n", result)
+}
+
+// renderCookies render the cookies of the options if the operation has cookie parameters.
+func (g *openapiGenerator) renderCookies(buf *bytes.Buffer, o *operation) {
+	for _, p := range o.params {
+		if p.In == "cookie" {
+			buf.WriteString("\t\tCookies: map[string]string{},\n")
+			return
+		}
+	}
+}
+
+// renderParamOptions render the assignment of the params to the options.
+func (g *openapiGenerator) renderParamOptions(buf *bytes.Buffer, o *operation, opts string) {
+	if len(o.params) == 0 {
+		return
+	}
+
+	var hasPath, hasQuery bool
+	for _, p := range o.params {
+		hasPath = hasPath || p.In == "path"
+		hasQuery = hasQuery || p.In == "query"
+	}
+
+	buf.WriteString("\tif params != nil {\n")
+
+	if hasPath {
+		fmt.Fprintf(buf, "\t\t%s.PathParams = params\n", opts)
+	}
+
+	if hasQuery {
+		fmt.Fprintf(buf, "\t\t%s.Query = params\n", opts)
+	}
+
+	for _, p := range o.params {
+		var target string
+		switch p.In {
+		case "header":
+			target = opts + ".Headers"
+		case "cookie":
+			target = opts + ".Cookies"
+		default:
+			continue
+		}
+
+		g.imports["fmt"] = true
+		field := "params." + paramField(p)
+
+		if p.Required {
+			fmt.Fprintf(buf, "\t\t%s[%q] = fmt.Sprint(%s)\n", target, p.Name, field)
+		} else {
+			fmt.Fprintf(buf, "\t\tif %s != nil {\n\t\t\t%s[%q] = fmt.Sprint(*%s)\n\t\t}\n", field, target, p.Name, field)
+		}
+	}
+
+	buf.WriteString("\t}\n\n")
+}
+
+// refType returns the type used by arguments and results, structs are passed by pointer.
+func (g *openapiGenerator) refType(typ string) string {
+	if g.isStruct(typ) {
+		return "*" + typ
+	}
+
+	return typ
+}
+
+// paramField returns the field name of a parameter, prefixed by its location when it's a header or cookie.
+func paramField(p *Parameter) string {
+	switch p.In {
+	case "header":
+		return "Header" + goName(p.Name)
+	case "cookie":
+		return "Cookie" + goName(p.Name)
+	}
+
+	return goName(p.Name)
+}
+
+// writeDoc write the doc comment of a type.
+func writeDoc(buf *bytes.Buffer, name, description string) {
+	if description = strings.TrimSpace(description); description == "" {
+		return
+	}
+
+	for i, line := range strings.Split(description, "\n") {
+		if i == 0 && !strings.HasPrefix(line, name+" ") {
+			line = name + " " + line
+		}
+
+		if line = strings.TrimSpace(line); line == "" {
+			buf.WriteString("//\n")
+		} else {
+			fmt.Fprintf(buf, "// %s\n", line)
+		}
+	}
+}
+
+// goName convert a name of the document into an exported go identifier, eg: pet_id is PetID.
+func goName(name string) string {
+	var (
+		words []string
+		word  []rune
+	)
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var sb strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+
+		rs := []rune(strings.ToLower(w))
+		rs[0] = unicode.ToUpper(rs[0])
+		sb.WriteString(string(rs))
+	}
+
+	s := sb.String()
+	if s != "" && unicode.IsDigit([]rune(s)[0]) {
+		s = "N" + s
+	}
+
+	return s
+}
@@ -0,0 +1,32 @@
+package gen_test
+
+import (
+	"github.com/dobyte/http/internal/gen"
+	"os"
+	"testing"
+)
+
+func TestGenerateOpenAPI(t *testing.T) {
+	data, err := os.ReadFile("testdata/petstore.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gen.GenerateOpenAPI(data, "petstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := os.ReadFile("testdata/petstore_http.go.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("GenerateOpenAPI() got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err = gen.GenerateOpenAPI([]byte(`{"swagger":"2.0"}`), "petstore"); err == nil {
+		t.Errorf("GenerateOpenAPI() with swagger 2.0 err = nil")
+	}
+}
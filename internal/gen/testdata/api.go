@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"github.com/dobyte/http"
+	"time"
+)
+
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+//go:generate go run github.com/dobyte/http/cmd/dobyte-http-gen -type UserAPI
+type UserAPI interface {
+	// GetUser returns the user of the id.
+	// @GET /users/{id}
+	// @Timeout 5s
+	// @Error APIError
+	GetUser(ctx context.Context, id int) (*User, error)
+
+	// ListUsers returns the users of the tenant.
+	// @GET /tenants/{tenant}/users
+	// @Header X-Tenant: {tenant}
+	// @Header Accept: application/json
+	ListUsers(ctx context.Context, tenant string, page, size int) ([]*User, error)
+
+	// CreateUser creates a user.
+	// @POST /users
+	// @Body user
+	CreateUser(ctx context.Context, user *User) (*User, error)
+
+	// DeleteUser deletes a user.
+	// @DELETE /users/{id}
+	DeleteUser(id int) error
+
+	// Export exports the users.
+	// @GET /users/export
+	Export(ctx context.Context, format string) (*http.Response, error)
+}
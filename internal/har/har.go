@@ -0,0 +1,373 @@
+// Package har implements the HTTP Archive 1.2 format, see http://www.softwareishard.com/blog/har-12-spec/.
+package har
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const Version = "1.2"
+
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+	Comment string  `json:"comment,omitempty"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Entry struct {
+	StartedDateTime time.Time `json:"startedDateTime"`
+	// Time is the total time of the request in milliseconds, the sum of the timings.
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           Cache    `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	ServerIPAddress string   `json:"serverIPAddress,omitempty"`
+	Connection      string   `json:"connection,omitempty"`
+	Comment         string   `json:"comment,omitempty"`
+	// Error is the error of a request failed without response, whose Response has status 0.
+	Error string `json:"_error,omitempty"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+type Cookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string  `json:"mimeType"`
+	Params   []Param `json:"params"`
+	Text     string  `json:"text"`
+	Comment  string  `json:"comment,omitempty"`
+}
+
+type Param struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type Cache struct{}
+
+// Timings are in milliseconds, -1 for the phases which don't apply to the request.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// Total returns the sum of the timings, excluding SSL which is part of Connect.
+func (t Timings) Total() float64 {
+	var total float64
+	for _, v := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if v > 0 {
+			total += v
+		}
+	}
+
+	return total
+}
+
+// NewRequest returns the HAR request of req, whose body is recorded up to limit bytes, or not at all for a negative limit.
+func NewRequest(req *http.Request, body []byte, size int64, limit int) Request {
+	r := Request{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: httpVersion(req.Proto),
+		Cookies:     make([]Cookie, 0),
+		Headers:     Headers(req.Header),
+		QueryString: Query(req.URL.RawQuery),
+		HeadersSize: -1,
+		BodySize:    size,
+	}
+
+	if req.Host != "" && req.Host != req.URL.Host {
+		r.Headers = append([]NameValue{{Name: "Host", Value: req.Host}}, r.Headers...)
+	}
+
+	for _, c := range req.Cookies() {
+		r.Cookies = append(r.Cookies, Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	if size <= 0 || limit < 0 {
+		return r
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+
+	r.PostData = &PostData{MimeType: contentType, Params: make([]Param, 0)}
+
+	if len(body) < int(size) {
+		r.PostData.Comment = truncated(len(body), size)
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		for _, nv := range Query(string(body)) {
+			r.PostData.Params = append(r.PostData.Params, Param{Name: nv.Name, Value: nv.Value})
+		}
+	case "multipart/form-data":
+		r.PostData.Params = multipartParams(body, params["boundary"], limit)
+	}
+
+	r.PostData.Text = string(bytes.ToValidUTF8(truncate(body, limit), []byte("�")))
+
+	return r
+}
+
+// NewResponse returns the HAR response of resp without content.
+func NewResponse(resp *http.Response) Response {
+	r := Response{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode))),
+		HTTPVersion: httpVersion(resp.Proto),
+		Cookies:     make([]Cookie, 0),
+		Headers:     Headers(resp.Header),
+		Content:     Content{Size: -1, MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	if r.StatusText == "" {
+		r.StatusText = http.StatusText(resp.StatusCode)
+	}
+
+	if r.Content.MimeType == "" {
+		r.Content.MimeType = "x-unknown"
+	}
+
+	for _, c := range resp.Cookies() {
+		cookie := Cookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain, HTTPOnly: c.HttpOnly, Secure: c.Secure}
+		if !c.Expires.IsZero() {
+			expires := c.Expires
+			cookie.Expires = &expires
+		}
+		r.Cookies = append(r.Cookies, cookie)
+	}
+
+	return r
+}
+
+// SetContent set the content of the response, body is the recorded part of the size bytes read,
+// and complete reports whether the body was read to the end. Binary bodies are base64 encoded.
+func (r *Response) SetContent(body []byte, size int64, complete bool) {
+	var comments []string
+
+	r.Content.Size, r.BodySize = size, size
+
+	if int64(len(body)) < size {
+		body, comments = trimRune(body), append(comments, truncated(len(body), size))
+	}
+
+	if !complete {
+		comments = append(comments, "the body wasn't read to the end")
+	}
+
+	r.Content.Comment = strings.Join(comments, ", ")
+
+	if utf8.Valid(body) {
+		r.Content.Text, r.Content.Encoding = string(body), ""
+	} else {
+		r.Content.Text, r.Content.Encoding = base64.StdEncoding.EncodeToString(body), "base64"
+	}
+}
+
+// Redact replace the values of the headers, matched case-insensitively, with value in the request and the response.
+// The cookies of the request are redacted with the Cookie header, and the ones of the response with Set-Cookie.
+func (e *Entry) Redact(headers []string, value string) {
+	if redactHeaders(e.Request.Headers, headers, value, "Cookie") {
+		for i := range e.Request.Cookies {
+			e.Request.Cookies[i].Value = value
+		}
+	}
+
+	if redactHeaders(e.Response.Headers, headers, value, "Set-Cookie") {
+		for i := range e.Response.Cookies {
+			e.Response.Cookies[i].Value = value
+		}
+	}
+}
+
+// redactHeaders redact the headers, and reports whether the cookie header is redacted.
+func redactHeaders(nvs []NameValue, headers []string, value, cookie string) bool {
+	var redactCookie bool
+
+	for _, name := range headers {
+		if strings.EqualFold(name, cookie) {
+			redactCookie = true
+		}
+
+		for i := range nvs {
+			if strings.EqualFold(nvs[i].Name, name) {
+				nvs[i].Value = value
+			}
+		}
+	}
+
+	return redactCookie
+}
+
+// Headers returns the header fields sorted by name.
+func Headers(header http.Header) []NameValue {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	nvs := make([]NameValue, 0, len(header))
+	for _, key := range keys {
+		for _, value := range header[key] {
+			nvs = append(nvs, NameValue{Name: key, Value: value})
+		}
+	}
+
+	return nvs
+}
+
+// Query returns the parameters of a query in their order.
+func Query(rawQuery string) []NameValue {
+	nvs := make([]NameValue, 0)
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(pair, "=")
+		if v, err := url.QueryUnescape(name); err == nil {
+			name = v
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+
+		nvs = append(nvs, NameValue{Name: name, Value: value})
+	}
+
+	return nvs
+}
+
+// multipartParams returns the parts of a multipart body, with the values of the fields which aren't files.
+func multipartParams(body []byte, boundary string, limit int) []Param {
+	params := make([]Param, 0)
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return params
+		}
+
+		param := Param{Name: part.FormName(), FileName: part.FileName(), ContentType: part.Header.Get("Content-Type")}
+		if param.FileName == "" {
+			value, _ := io.ReadAll(io.LimitReader(part, int64(limit)))
+			param.Value = string(bytes.ToValidUTF8(value, []byte("�")))
+		}
+
+		params = append(params, param)
+	}
+}
+
+func httpVersion(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+
+	return proto
+}
+
+func truncate(body []byte, limit int) []byte {
+	if len(body) > limit {
+		return body[:limit]
+	}
+
+	return body
+}
+
+// trimRune remove the incomplete rune at the end of a truncated body.
+func trimRune(body []byte) []byte {
+	for i := len(body) - 1; i >= 0 && i >= len(body)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(body[i]) {
+			if !utf8.FullRune(body[i:]) {
+				return body[:i]
+			}
+			break
+		}
+	}
+
+	return body
+}
+
+func truncated(recorded int, size int64) string {
+	return "truncated, " + strconv.Itoa(recorded) + " of " + strconv.FormatInt(size, 10) + " bytes recorded"
+}
@@ -0,0 +1,71 @@
+package har_test
+
+import (
+	"bytes"
+	"github.com/dobyte/http/internal/har"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewRequest(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		writer = multipart.NewWriter(&buf)
+	)
+
+	writer.WriteField("title", "hello")
+	part, _ := writer.CreateFormFile("file", "a.bin")
+	part.Write([]byte{0xff, 0x00})
+	writer.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, "https://example.com/upload?b=2&a=1&a=%20", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Cookie", "sid=abc; theme=dark")
+
+	r := har.NewRequest(req, buf.Bytes(), int64(buf.Len()), 1024)
+
+	if got := r.QueryString; len(got) != 3 || got[0].Name != "b" || got[2].Value != " " {
+		t.Errorf("QueryString = %v", got)
+	}
+
+	if got := r.Cookies; len(got) != 2 || got[1].Name != "theme" || got[1].Value != "dark" {
+		t.Errorf("Cookies = %v", got)
+	}
+
+	params := r.PostData.Params
+	if len(params) != 2 || params[0].Value != "hello" || params[1].FileName != "a.bin" || params[1].Value != "" {
+		t.Errorf("PostData.Params = %v", params)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = har.NewRequest(req, []byte("name=a%20b&x=1"), 20, 8)
+
+	if r.PostData.Text != "name=a%2" || r.PostData.Comment != "truncated, 14 of 20 bytes recorded" {
+		t.Errorf("PostData = %+v", r.PostData)
+	}
+
+	if params := r.PostData.Params; len(params) != 2 || params[0].Value != "a b" {
+		t.Errorf("PostData.Params = %v", params)
+	}
+}
+
+func TestResponse_SetContent(t *testing.T) {
+	var r har.Response
+
+	r.SetContent([]byte("héllo"), 6, true)
+	if r.Content.Text != "héllo" || r.Content.Encoding != "" || r.Content.Comment != "" {
+		t.Errorf("SetContent() = %+v", r.Content)
+	}
+
+	r.SetContent([]byte("h\xc3"), 6, false)
+	if r.Content.Text != "h" || !strings.HasPrefix(r.Content.Comment, "truncated, 2 of 6 bytes recorded, ") {
+		t.Errorf("SetContent() truncated = %+v", r.Content)
+	}
+
+	r.SetContent([]byte{0xff, 0xfe}, 2, true)
+	if r.Content.Text != "//4=" || r.Content.Encoding != "base64" {
+		t.Errorf("SetContent() binary = %+v", r.Content)
+	}
+}
@@ -0,0 +1,157 @@
+package har
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timer records the phases of a request with a httptrace.ClientTrace, a retried request restarts it.
+type Timer struct {
+	mu sync.Mutex
+	phases
+}
+
+type phases struct {
+	getConn      time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wrote        time.Time
+	firstByte    time.Time
+	remoteAddr   string
+	localAddr    string
+}
+
+// Trace returns the trace which feeds the timer.
+func (t *Timer) Trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			t.phases = phases{getConn: time.Now()}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.set(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.set(&t.dnsDone)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			// dialers racing ipv4 and ipv6 start more than once
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(string, string, error) {
+			t.set(&t.connectDone)
+		},
+		TLSHandshakeStart: func() {
+			t.set(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.set(&t.tlsDone)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			t.gotConn = time.Now()
+			if info.Conn != nil {
+				t.remoteAddr, t.localAddr = info.Conn.RemoteAddr().String(), info.Conn.LocalAddr().String()
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.set(&t.wrote)
+		},
+		GotFirstResponseByte: func() {
+			t.set(&t.firstByte)
+		},
+	}
+}
+
+func (t *Timer) set(field *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	*field = time.Now()
+}
+
+// Addrs returns the remote and local addresses of the connection.
+func (t *Timer) Addrs() (remote, local string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.remoteAddr, t.localAddr
+}
+
+// Timings returns the timings of the request sent at start, whose response headers arrived at headers
+// and body was read at end. The phases not traced, eg: by a transport without network, are derived from them.
+func (t *Timer) Timings(start, headers, end time.Time) Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+
+	if t.getConn.IsZero() || t.gotConn.IsZero() {
+		timings.Wait = ms(headers.Sub(start))
+		timings.Receive = ms(end.Sub(headers))
+		return timings
+	}
+
+	switch {
+	case !t.dnsStart.IsZero():
+		timings.Blocked = ms(t.dnsStart.Sub(t.getConn))
+	case !t.connectStart.IsZero():
+		timings.Blocked = ms(t.connectStart.Sub(t.getConn))
+	default:
+		timings.Blocked = ms(t.gotConn.Sub(t.getConn))
+	}
+
+	if !t.dnsStart.IsZero() && !t.dnsDone.IsZero() {
+		timings.DNS = ms(t.dnsDone.Sub(t.dnsStart))
+	}
+
+	if !t.connectStart.IsZero() && !t.connectDone.IsZero() {
+		timings.Connect = ms(t.connectDone.Sub(t.connectStart))
+	}
+
+	if !t.tlsStart.IsZero() && !t.tlsDone.IsZero() {
+		timings.SSL = ms(t.tlsDone.Sub(t.tlsStart))
+		if timings.Connect >= 0 {
+			timings.Connect += timings.SSL
+		}
+	}
+
+	firstByte, wrote := t.firstByte, t.wrote
+	if firstByte.IsZero() {
+		firstByte = headers
+	}
+
+	if wrote.IsZero() {
+		wrote = t.gotConn
+	}
+
+	timings.Send = ms(wrote.Sub(t.gotConn))
+	timings.Wait = ms(firstByte.Sub(wrote))
+	timings.Receive = ms(end.Sub(firstByte))
+
+	return timings
+}
+
+func ms(d time.Duration) float64 {
+	if d < 0 {
+		return 0
+	}
+
+	return float64(d) / float64(time.Millisecond)
+}
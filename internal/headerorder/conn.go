@@ -0,0 +1,250 @@
+// Package headerorder reorders the header lines of HTTP/1.x requests written to a connection,
+// since net/http always writes headers sorted by key.
+package headerorder
+
+import (
+	"bytes"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxHeaderSize is the size of a header block beyond which it's written unchanged.
+const maxHeaderSize = 1 << 20
+
+type state int
+
+const (
+	stateHeader state = iota
+	stateBody
+	stateChunkSize
+	stateChunkData
+	stateChunkCRLF
+	stateTrailer
+	statePassThrough
+)
+
+// Conn is a net.Conn which reorders the header lines of the requests written to it.
+type Conn struct {
+	net.Conn
+	order func() []string
+
+	mu      sync.Mutex
+	state   state
+	buf     []byte
+	remain  int64
+	trailer []byte
+}
+
+// Wrap returns a conn which reorders the header lines of the requests by the keys returned by order,
+// the headers not in the order are written after the ordered ones in their original order.
+// It writes the bytes unchanged once they don't look like a HTTP/1.x request, eg: a TLS handshake.
+func Wrap(conn net.Conn, order func() []string) net.Conn {
+	return &Conn{Conn: conn, order: order}
+}
+
+func (c *Conn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var out []byte
+	if err := c.feed(p, &out); err != nil {
+		return 0, err
+	}
+
+	if len(out) > 0 {
+		if _, err := c.Conn.Write(out); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// feed consume p and append the bytes ready to write to out.
+func (c *Conn) feed(p []byte, out *[]byte) error {
+	for len(p) > 0 {
+		switch c.state {
+		case statePassThrough:
+			*out = append(*out, p...)
+			return nil
+		case stateHeader:
+			if len(c.buf) == 0 && (p[0] < 'A' || p[0] > 'Z') {
+				c.state = statePassThrough
+				continue
+			}
+
+			c.buf = append(c.buf, p...)
+			p = nil
+
+			end := bytes.Index(c.buf, []byte("\r\n\r\n"))
+			if end < 0 {
+				if len(c.buf) > maxHeaderSize {
+					*out, c.buf, c.state = append(*out, c.buf...), nil, statePassThrough
+				}
+				return nil
+			}
+
+			header, rest := c.buf[:end+4], c.buf[end+4:]
+			c.buf = nil
+
+			*out = append(*out, c.reorder(header)...)
+			c.startBody(header)
+			p = rest
+		case stateBody:
+			n := int64(len(p))
+			if n > c.remain {
+				n = c.remain
+			}
+
+			*out = append(*out, p[:n]...)
+			p, c.remain = p[n:], c.remain-n
+
+			if c.remain == 0 {
+				c.state = stateHeader
+			}
+		case stateChunkSize, stateTrailer:
+			i := bytes.IndexByte(p, '\n')
+			if i < 0 {
+				c.trailer = append(c.trailer, p...)
+				*out = append(*out, p...)
+				return nil
+			}
+
+			line := string(append(c.trailer, p[:i]...))
+			*out = append(*out, p[:i+1]...)
+			p, c.trailer = p[i+1:], nil
+
+			line = strings.TrimSpace(line)
+			if c.state == stateTrailer {
+				if line == "" {
+					c.state = stateHeader
+				}
+				continue
+			}
+
+			if j := strings.IndexByte(line, ';'); j >= 0 {
+				line = line[:j]
+			}
+
+			size, err := strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+			switch {
+			case err != nil:
+				c.state = statePassThrough
+			case size == 0:
+				c.state = stateTrailer
+			default:
+				c.state, c.remain = stateChunkData, size
+			}
+		case stateChunkData:
+			n := int64(len(p))
+			if n > c.remain {
+				n = c.remain
+			}
+
+			*out = append(*out, p[:n]...)
+			p, c.remain = p[n:], c.remain-n
+
+			if c.remain == 0 {
+				c.state, c.remain = stateChunkCRLF, 2
+			}
+		case stateChunkCRLF:
+			n := int64(len(p))
+			if n > c.remain {
+				n = c.remain
+			}
+
+			*out = append(*out, p[:n]...)
+			p, c.remain = p[n:], c.remain-n
+
+			if c.remain == 0 {
+				c.state = stateChunkSize
+			}
+		}
+	}
+
+	return nil
+}
+
+// startBody determine how the body following the header is framed.
+func (c *Conn) startBody(header []byte) {
+	c.state = stateHeader
+
+	for _, line := range strings.Split(string(header), "\r\n")[1:] {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(key)) {
+		case "Transfer-Encoding":
+			if strings.Contains(strings.ToLower(value), "chunked") {
+				c.state = stateChunkSize
+				return
+			}
+		case "Content-Length":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil && n > 0 {
+				c.state, c.remain = stateBody, n
+			}
+		}
+	}
+}
+
+// reorder sort the header lines of a header block.
+func (c *Conn) reorder(header []byte) []byte {
+	order := c.order()
+	if len(order) == 0 {
+		return header
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(header), "\r\n\r\n"), "\r\n")
+	if len(lines) < 2 {
+		return header
+	}
+
+	rank := make(map[string]int, len(order))
+	for i, key := range order {
+		if _, ok := rank[textproto.CanonicalMIMEHeaderKey(key)]; !ok {
+			rank[textproto.CanonicalMIMEHeaderKey(key)] = i
+		}
+	}
+
+	var (
+		ordered   = make([][]string, len(order))
+		unordered []string
+	)
+
+	for _, line := range lines[1:] {
+		key, _, _ := strings.Cut(line, ":")
+		if i, ok := rank[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(key))]; ok {
+			ordered[i] = append(ordered[i], line)
+		} else {
+			unordered = append(unordered, line)
+		}
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(header))
+	sb.WriteString(lines[0])
+	sb.WriteString("\r\n")
+
+	for _, group := range ordered {
+		for _, line := range group {
+			sb.WriteString(line)
+			sb.WriteString("\r\n")
+		}
+	}
+
+	for _, line := range unordered {
+		sb.WriteString(line)
+		sb.WriteString("\r\n")
+	}
+
+	sb.WriteString("\r\n")
+
+	return []byte(sb.String())
+}
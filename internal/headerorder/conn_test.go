@@ -0,0 +1,63 @@
+package headerorder_test
+
+import (
+	"bytes"
+	"github.com/dobyte/http/internal/headerorder"
+	"net"
+	"testing"
+)
+
+type recorder struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	return r.buf.Write(p)
+}
+
+func TestConn_Write(t *testing.T) {
+	var (
+		rec  = &recorder{}
+		conn = headerorder.Wrap(rec, func() []string { return []string{"user-agent", "Accept", "Host"} })
+	)
+
+	writes := []string{
+		"POST /a HTTP/1.1\r\nHost: example.com\r\nAccept: */*\r\nContent-Length: 4\r\n",
+		"User-Agent: test\r\nAccept: text/html\r\n\r\nbo",
+		"dy",
+		"POST /b HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\nUser-Agent: test\r\n\r\n",
+		"4\r\nHost\r\n0\r\n\r\n",
+		"GET /c HTTP/1.1\r\nX-A: 1\r\nAccept: a\r\n\r\n",
+	}
+
+	for _, w := range writes {
+		if n, err := conn.Write([]byte(w)); err != nil || n != len(w) {
+			t.Fatalf("Write() = %d, %v", n, err)
+		}
+	}
+
+	want := "POST /a HTTP/1.1\r\nUser-Agent: test\r\nAccept: */*\r\nAccept: text/html\r\nHost: example.com\r\nContent-Length: 4\r\n\r\nbody" +
+		"POST /b HTTP/1.1\r\nUser-Agent: test\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\n4\r\nHost\r\n0\r\n\r\n" +
+		"GET /c HTTP/1.1\r\nAccept: a\r\nX-A: 1\r\n\r\n"
+
+	if got := rec.buf.String(); got != want {
+		t.Errorf("Write() got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestConn_PassThrough(t *testing.T) {
+	var (
+		rec  = &recorder{}
+		conn = headerorder.Wrap(rec, func() []string { return []string{"Accept"} })
+		tls  = []byte{0x16, 0x03, 0x01, 0x00, 0x05, 'H', 'o', 's', 't', ':'}
+	)
+
+	if _, err := conn.Write(tls); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(rec.buf.Bytes(), tls) {
+		t.Errorf("Write() = %q, want %q", rec.buf.Bytes(), tls)
+	}
+}
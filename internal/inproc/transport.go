@@ -0,0 +1,375 @@
+// Package inproc implements a http.RoundTripper which serves the requests by a http.Handler in-process.
+package inproc
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultRemoteAddr = "192.0.2.1:1234"
+
+// Transport serves the requests by Handler without network. The response is returned as soon as the handler
+// writes its header, and its body streams what the handler writes, so the handler runs along with the reading
+// of the body. The context of the request passed to the handler is canceled when the request is canceled,
+// or the response body is closed.
+type Transport struct {
+	Handler http.Handler
+	// RemoteAddr is the remote address of the requests seen by the handler, defaults to 192.0.2.1:1234.
+	RemoteAddr string
+}
+
+// RoundTrip Serve the request by the handler.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Handler == nil {
+		closeBody(req)
+		return nil, errors.New("inproc: no handler")
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+
+	sreq, err := t.serverRequest(ctx, req)
+	if err != nil {
+		cancel()
+		closeBody(req)
+		return nil, err
+	}
+
+	w := newResponseWriter(req, sreq, cancel)
+
+	go w.serve(t.Handler)
+
+	select {
+	case <-w.ready:
+		return w.resp, w.err
+	case <-req.Context().Done():
+		cancel()
+		return nil, req.Context().Err()
+	}
+}
+
+// serverRequest returns the request as it's received by a server.
+func (t *Transport) serverRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	sreq := req.Clone(ctx)
+
+	u, err := url.ParseRequestURI(req.URL.RequestURI())
+	if err != nil {
+		return nil, err
+	}
+
+	sreq.URL, sreq.RequestURI = u, req.URL.RequestURI()
+	sreq.Proto, sreq.ProtoMajor, sreq.ProtoMinor = "HTTP/1.1", 1, 1
+	sreq.GetBody, sreq.Close = nil, false
+
+	sreq.RemoteAddr = t.RemoteAddr
+	if sreq.RemoteAddr == "" {
+		sreq.RemoteAddr = defaultRemoteAddr
+	}
+
+	if sreq.Host == "" {
+		sreq.Host = req.URL.Host
+	}
+	sreq.Header.Del("Host")
+
+	if sreq.Body == nil {
+		sreq.Body = http.NoBody
+	}
+
+	if req.URL.Scheme == "https" {
+		sreq.TLS = &tls.ConnectionState{
+			Version:           tls.VersionTLS12,
+			HandshakeComplete: true,
+			ServerName:        req.URL.Hostname(),
+		}
+	}
+
+	return sreq, nil
+}
+
+// responseWriter streams the response written by a handler through a pipe.
+type responseWriter struct {
+	req    *http.Request
+	sreq   *http.Request
+	cancel context.CancelFunc
+	header http.Header
+	pr     *io.PipeReader
+	pw     *io.PipeWriter
+
+	mu          sync.Mutex
+	wroteHeader bool
+	hijacked    bool
+	ready       chan struct{}
+	resp        *http.Response
+	err         error
+}
+
+func newResponseWriter(req, sreq *http.Request, cancel context.CancelFunc) *responseWriter {
+	pr, pw := io.Pipe()
+
+	return &responseWriter{
+		req:    req,
+		sreq:   sreq,
+		cancel: cancel,
+		header: make(http.Header),
+		pr:     pr,
+		pw:     pw,
+		ready:  make(chan struct{}),
+	}
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeHeaderLocked(status)
+}
+
+func (w *responseWriter) writeHeaderLocked(status int) {
+	if w.wroteHeader || w.hijacked {
+		return
+	}
+
+	// informational responses other than 101 aren't returned by http.Client
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		return
+	}
+
+	w.wroteHeader = true
+
+	header := w.header.Clone()
+	resp := &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          &body{pr: w.pr, cancel: w.cancel},
+		ContentLength: -1,
+		Request:       w.req,
+	}
+
+	if n, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+		resp.ContentLength = n
+	}
+
+	for _, key := range header.Values("Trailer") {
+		for _, k := range strings.Split(key, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				if resp.Trailer == nil {
+					resp.Trailer = make(http.Header)
+				}
+				resp.Trailer[http.CanonicalHeaderKey(k)] = nil
+			}
+		}
+	}
+	resp.Header.Del("Trailer")
+
+	if w.req.Method == http.MethodHead || status == http.StatusNoContent || status == http.StatusNotModified {
+		resp.Body, resp.ContentLength = http.NoBody, 0
+		if status != http.StatusNoContent {
+			resp.ContentLength = -1
+		}
+	}
+
+	w.resolve(resp, nil)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+
+	if w.hijacked {
+		w.mu.Unlock()
+		return 0, http.ErrHijacked
+	}
+
+	if !w.wroteHeader {
+		if _, ok := w.header["Content-Type"]; !ok && w.header.Get("Transfer-Encoding") == "" {
+			w.header.Set("Content-Type", http.DetectContentType(p))
+		}
+		w.writeHeaderLocked(http.StatusOK)
+	}
+
+	w.mu.Unlock()
+
+	if w.resp.Body == http.NoBody {
+		return len(p), nil
+	}
+
+	return w.pw.Write(p)
+}
+
+func (w *responseWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+}
+
+// Hijack returns a connection to the client, which reads the response written to it as from a server.
+// A 101 Switching Protocols response has a body which is an io.ReadWriteCloser of the connection.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.hijacked {
+		return nil, nil, http.ErrHijacked
+	}
+
+	if w.wroteHeader {
+		return nil, nil, errors.New("inproc: hijack after the header was written")
+	}
+
+	w.hijacked = true
+
+	server, client := net.Pipe()
+
+	go func() {
+		reader := bufio.NewReader(client)
+
+		resp, err := http.ReadResponse(reader, w.req)
+		if err != nil {
+			_ = client.Close()
+			w.resolve(nil, fmt.Errorf("inproc: read hijacked response: %w", err))
+			return
+		}
+
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			resp.Body = &upgradedBody{reader: reader, Conn: client}
+		} else {
+			resp.Body = &hijackedBody{ReadCloser: resp.Body, conn: client}
+		}
+
+		w.resolve(resp, nil)
+	}()
+
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+// resolve set the result of the round trip once.
+func (w *responseWriter) resolve(resp *http.Response, err error) {
+	select {
+	case <-w.ready:
+	default:
+		w.resp, w.err = resp, err
+		close(w.ready)
+	}
+}
+
+// serve run the handler, and complete the response when it returns.
+func (w *responseWriter) serve(handler http.Handler) {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-w.sreq.Context().Done():
+			_ = w.pw.CloseWithError(w.sreq.Context().Err())
+		case <-done:
+		}
+	}()
+
+	defer closeBody(w.sreq)
+
+	defer func() {
+		if v := recover(); v != nil {
+			err := fmt.Errorf("inproc: handler panic: %v", v)
+			if v == http.ErrAbortHandler {
+				err = errors.New("inproc: handler aborted the response")
+			}
+
+			w.mu.Lock()
+			hijacked := w.hijacked
+			w.mu.Unlock()
+
+			if !hijacked {
+				w.resolve(nil, err)
+				_ = w.pw.CloseWithError(err)
+			}
+		}
+	}()
+
+	handler.ServeHTTP(w, w.sreq)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.hijacked {
+		return
+	}
+
+	if !w.wroteHeader {
+		if _, ok := w.header["Content-Length"]; !ok {
+			w.header.Set("Content-Length", "0")
+		}
+		w.writeHeaderLocked(http.StatusOK)
+	}
+
+	if w.resp.Trailer != nil {
+		for key := range w.resp.Trailer {
+			w.resp.Trailer[key] = w.header.Values(key)
+		}
+	}
+
+	for key, values := range w.header {
+		if strings.HasPrefix(key, http.TrailerPrefix) {
+			if w.resp.Trailer == nil {
+				w.resp.Trailer = make(http.Header)
+			}
+			w.resp.Trailer[http.CanonicalHeaderKey(strings.TrimPrefix(key, http.TrailerPrefix))] = values
+		}
+	}
+
+	_ = w.pw.Close()
+}
+
+// body is the body of a response, closing it cancels the request of the handler.
+type body struct {
+	pr     *io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	return b.pr.Read(p)
+}
+
+func (b *body) Close() error {
+	b.cancel()
+	return b.pr.Close()
+}
+
+type upgradedBody struct {
+	reader *bufio.Reader
+	net.Conn
+}
+
+func (b *upgradedBody) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+type hijackedBody struct {
+	io.ReadCloser
+	conn net.Conn
+}
+
+func (b *hijackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	_ = b.conn.Close()
+	return err
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -1,10 +1,8 @@
 package multipart
 
 import (
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
-	"github.com/dobyte/http/internal"
+	"github.com/dobyte/http/internal/codec"
 	"io"
 	"mime/multipart"
 	"net/textproto"
@@ -26,12 +24,18 @@ var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
 
 type Writer struct {
 	*multipart.Writer
+	codecs *codec.Registry
 }
 
 func NewWriter(w io.Writer) *Writer {
 	return &Writer{Writer: multipart.NewWriter(w)}
 }
 
+// SetCodecs sets the codec registry used to encode complex field values.
+func (w *Writer) SetCodecs(codecs *codec.Registry) {
+	w.codecs = codecs
+}
+
 func (w *Writer) WriteField(fieldName string, fieldValue interface{}, fieldType FieldType) (err error) {
 	var (
 		buf  []byte
@@ -64,21 +68,19 @@ func (w *Writer) WriteField(fieldName string, fieldValue interface{}, fieldType
 		buf = []byte(rv.String())
 		fieldType = FieldTypeNone
 	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Interface:
-		switch fieldType {
-		case FieldTypeXml:
-			buf, err = xml.Marshal(rv.Interface())
-			if err != nil {
-				return
-			}
-		case FieldTypeFormUrlEncoded:
-			buf = []byte(internal.BuildParams(rv.Interface()))
-		default:
-			buf, err = json.Marshal(rv.Interface())
-			if err != nil {
-				return
-			}
+		if fieldType == FieldTypeNone {
 			fieldType = FieldTypeJson
 		}
+
+		c, ok := w.codecs.Lookup(string(fieldType))
+		if !ok {
+			return fmt.Errorf("multipart: no codec registered for %q", fieldType)
+		}
+
+		buf, err = c.Marshal(rv.Interface())
+		if err != nil {
+			return
+		}
 	}
 
 	h := make(textproto.MIMEHeader)
@@ -0,0 +1,406 @@
+package query
+
+import (
+	"encoding"
+	"fmt"
+	"github.com/dobyte/http/internal/xconv"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArrayFormat is the way slices and arrays are encoded.
+type ArrayFormat int
+
+const (
+	ArrayRepeat   ArrayFormat = iota // ids=1&ids=2
+	ArrayBrackets                    // ids[]=1&ids[]=2
+	ArrayIndices                     // ids[0]=1&ids[1]=2
+	ArrayComma                       // ids=1,2
+)
+
+// NestedFormat is the way nested structs and maps are encoded.
+type NestedFormat int
+
+const (
+	NestedBrackets NestedFormat = iota // user[name]=fuxiao
+	NestedDots                         // user.name=fuxiao
+)
+
+const (
+	TimeFormatUnix      = "unix"
+	TimeFormatUnixMilli = "unixmilli"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Encoder encodes structs, maps and url.Values into url query strings.
+//
+// Struct fields are named by the url tag, then the json tag, then the field name,
+// eg: `url:"name,omitempty"`. Besides omitempty, the tag accepts the array formats
+// repeat, brackets, indices and comma, and the time formats unix and unixmilli.
+type Encoder struct {
+	ArrayFormat  ArrayFormat
+	NestedFormat NestedFormat
+	// TimeFormat is the layout of time.Time values, or TimeFormatUnix, TimeFormatUnixMilli.
+	// Defaults to time.RFC3339.
+	TimeFormat string
+}
+
+type fieldOptions struct {
+	omitEmpty   bool
+	arrayFormat ArrayFormat
+	timeFormat  string
+}
+
+// Encode encodes v into a query string sorted by key, strings and bytes are returned as they are.
+// As the former BuildParams did, a []interface{} is the variadic data of a request whose first item is encoded,
+// and values which are neither structs nor maps, eg: 123 or []int{1, 2}, are converted to strings.
+func (e *Encoder) Encode(v interface{}) (string, error) {
+	switch s := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	case url.Values, map[string][]string:
+	case []interface{}:
+		if len(s) == 0 {
+			return "", nil
+		}
+		return e.Encode(s[0])
+	default:
+		switch indirect(reflect.ValueOf(v)).Kind() {
+		case reflect.Struct, reflect.Map, reflect.Ptr, reflect.Interface:
+		default:
+			return xconv.String(v), nil
+		}
+	}
+
+	values, err := e.Values(v)
+	if err != nil {
+		return "", err
+	}
+
+	return values.Encode(), nil
+}
+
+// Values encodes v into url.Values, v must be a struct, a map with string keys or url.Values.
+func (e *Encoder) Values(v interface{}) (url.Values, error) {
+	values := make(url.Values)
+
+	switch s := v.(type) {
+	case nil:
+		return values, nil
+	case url.Values:
+		for key, items := range s {
+			values[key] = append([]string(nil), items...)
+		}
+		return values, nil
+	case map[string][]string:
+		return e.Values(url.Values(s))
+	case string:
+		return url.ParseQuery(s)
+	case []byte:
+		return url.ParseQuery(string(s))
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return values, nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map:
+		if err := e.encode(values, "", rv, fieldOptions{arrayFormat: e.ArrayFormat}); err != nil {
+			return nil, err
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("query: can't encode %T, want struct or map", v)
+	}
+}
+
+// encode add the value rv to values with the key.
+func (e *Encoder) encode(values url.Values, key string, rv reflect.Value, opts fieldOptions) error {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			if !opts.omitEmpty && key != "" {
+				values.Add(key, "")
+			}
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	if opts.omitEmpty && isEmpty(rv) {
+		return nil
+	}
+
+	if s, ok, err := e.scalar(rv, opts); ok || err != nil {
+		if err != nil {
+			return fmt.Errorf("query: encode %q: %w", key, err)
+		}
+		values.Add(key, s)
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		return e.encodeStruct(values, key, rv)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("query: encode %q: map key must be string", key)
+		}
+
+		iter := rv.MapRange()
+		for iter.Next() {
+			if err := e.encode(values, e.nest(key, iter.Key().String()), iter.Value(), fieldOptions{arrayFormat: e.ArrayFormat, timeFormat: opts.timeFormat}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		return e.encodeArray(values, key, rv, opts)
+	default:
+		return fmt.Errorf("query: encode %q: unsupported type %s", key, rv.Type())
+	}
+}
+
+// encodeStruct add the exported fields of a struct.
+func (e *Encoder) encodeStruct(values url.Values, key string, rv reflect.Value) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, opts, ok := e.parseTag(field)
+		if !ok {
+			continue
+		}
+
+		fv := rv.Field(i)
+
+		if field.Anonymous && name == "" {
+			if fv = indirect(fv); fv.Kind() == reflect.Ptr {
+				continue
+			}
+
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				if err := e.encodeStruct(values, key, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if err := e.encode(values, e.nest(key, name), fv, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeArray add the elements of a slice or an array.
+func (e *Encoder) encodeArray(values url.Values, key string, rv reflect.Value, opts fieldOptions) error {
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		values.Add(key, string(rv.Bytes()))
+		return nil
+	}
+
+	elemOpts := fieldOptions{arrayFormat: e.ArrayFormat, timeFormat: opts.timeFormat}
+
+	if opts.arrayFormat == ArrayComma {
+		items := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			s, ok, err := e.scalar(indirect(rv.Index(i)), elemOpts)
+			if err != nil {
+				return fmt.Errorf("query: encode %q: %w", key, err)
+			}
+			if !ok {
+				return fmt.Errorf("query: encode %q: comma format requires scalar elements", key)
+			}
+			items = append(items, s)
+		}
+		values.Add(key, strings.Join(items, ","))
+		return nil
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		var (
+			elemKey = key
+			elem    = indirect(rv.Index(i))
+		)
+
+		switch _, scalar, _ := e.scalar(elem, elemOpts); {
+		case opts.arrayFormat == ArrayIndices, !scalar && opts.arrayFormat == ArrayRepeat:
+			elemKey = key + "[" + strconv.Itoa(i) + "]"
+		case opts.arrayFormat == ArrayBrackets:
+			elemKey = key + "[]"
+		}
+
+		if err := e.encode(values, elemKey, elem, elemOpts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scalar format a value that is encoded as a single string.
+func (e *Encoder) scalar(rv reflect.Value, opts fieldOptions) (string, bool, error) {
+	if !rv.IsValid() {
+		return "", true, nil
+	}
+
+	if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return "", false, nil
+	}
+
+	if rv.Type() == timeType {
+		return e.formatTime(rv.Interface().(time.Time), opts.timeFormat), true, nil
+	}
+
+	if rv.Type().Implements(textMarshalerType) {
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), true, err
+	}
+
+	if rv.CanAddr() && reflect.PointerTo(rv.Type()).Implements(textMarshalerType) {
+		b, err := rv.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), true, err
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), true, nil
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(rv.Complex(), 'f', -1, 128), true, nil
+	}
+
+	return "", false, nil
+}
+
+// formatTime format t by the layout of the field, or the layout of the encoder.
+func (e *Encoder) formatTime(t time.Time, layout string) string {
+	if layout == "" {
+		layout = e.TimeFormat
+	}
+
+	switch layout {
+	case "":
+		return t.Format(time.RFC3339)
+	case TimeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeFormatUnixMilli:
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	default:
+		return t.Format(layout)
+	}
+}
+
+// nest join the parent key and the child key.
+func (e *Encoder) nest(parent, child string) string {
+	switch {
+	case parent == "":
+		return child
+	case e.NestedFormat == NestedDots:
+		return parent + "." + child
+	default:
+		return parent + "[" + child + "]"
+	}
+}
+
+// parseTag parse the url tag of a field, falls back to the json tag.
+func (e *Encoder) parseTag(field reflect.StructField) (name string, opts fieldOptions, ok bool) {
+	opts.arrayFormat = e.ArrayFormat
+
+	tag, found := field.Tag.Lookup("url")
+	if !found {
+		tag, found = field.Tag.Lookup("json")
+	}
+
+	if tag == "-" {
+		return "", opts, false
+	}
+
+	if !found {
+		return "", opts, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty":
+			opts.omitEmpty = true
+		case "repeat":
+			opts.arrayFormat = ArrayRepeat
+		case "brackets":
+			opts.arrayFormat = ArrayBrackets
+		case "indices":
+			opts.arrayFormat = ArrayIndices
+		case "comma":
+			opts.arrayFormat = ArrayComma
+		case TimeFormatUnix, TimeFormatUnixMilli:
+			opts.timeFormat = option
+		}
+	}
+
+	if layout, ok := field.Tag.Lookup("layout"); ok {
+		opts.timeFormat = layout
+	}
+
+	return parts[0], opts, true
+}
+
+// indirect dereference pointers and interfaces, nil is kept as it is.
+func indirect(rv reflect.Value) reflect.Value {
+	for (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	return rv
+}
+
+// isEmpty determine if the value is empty for omitempty.
+func isEmpty(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+
+	if rv.Type() == timeType {
+		return rv.Interface().(time.Time).IsZero()
+	}
+
+	return rv.IsZero()
+}
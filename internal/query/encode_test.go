@@ -0,0 +1,99 @@
+package query_test
+
+import (
+	"github.com/dobyte/http/internal/query"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type Address struct {
+	City string `url:"city"`
+	Zip  string `url:"zip,omitempty"`
+}
+
+type User struct {
+	ID        int64     `url:"id"`
+	Name      string    `json:"name"`
+	Tags      []string  `url:"tags"`
+	Roles     []int     `url:"roles,comma"`
+	Address   Address   `url:"address"`
+	Nickname  string    `url:"nickname,omitempty"`
+	CreatedAt time.Time `url:"created_at,unix"`
+	Ignored   string    `url:"-"`
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	user := &User{
+		ID:        9007199254740993,
+		Name:      "fu xiao",
+		Tags:      []string{"a", "b"},
+		Roles:     []int{1, 2},
+		Address:   Address{City: "chengdu"},
+		CreatedAt: time.Unix(1628040326, 0),
+		Ignored:   "ignored",
+	}
+
+	tests := []struct {
+		name    string
+		encoder *query.Encoder
+		data    interface{}
+		want    string
+	}{
+		{
+			name:    "struct with repeated keys",
+			encoder: &query.Encoder{},
+			data:    user,
+			want:    "address%5Bcity%5D=chengdu&created_at=1628040326&id=9007199254740993&name=fu+xiao&roles=1%2C2&tags=a&tags=b",
+		},
+		{
+			name:    "struct with brackets and dots",
+			encoder: &query.Encoder{ArrayFormat: query.ArrayBrackets, NestedFormat: query.NestedDots},
+			data:    user,
+			want:    "address.city=chengdu&created_at=1628040326&id=9007199254740993&name=fu+xiao&roles=1%2C2&tags%5B%5D=a&tags%5B%5D=b",
+		},
+		{
+			name:    "map with nested map",
+			encoder: &query.Encoder{ArrayFormat: query.ArrayIndices},
+			data:    map[string]interface{}{"b": []int{1, 2}, "a": map[string]string{"x": "1"}},
+			want:    "a%5Bx%5D=1&b%5B0%5D=1&b%5B1%5D=2",
+		},
+		{
+			name:    "url values",
+			encoder: &query.Encoder{},
+			data:    url.Values{"b": {"2"}, "a": {"1", "3"}},
+			want:    "a=1&a=3&b=2",
+		},
+		{
+			name:    "raw string",
+			encoder: &query.Encoder{},
+			data:    "is_force=true",
+			want:    "is_force=true",
+		},
+		{
+			name:    "variadic data",
+			encoder: &query.Encoder{},
+			data:    []interface{}{map[string]int{"id": 1}, "ignored"},
+			want:    "id=1",
+		},
+		{
+			name:    "scalar",
+			encoder: &query.Encoder{},
+			data:    123,
+			want:    "123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.encoder.Encode(tt.data)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Encode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
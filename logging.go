@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogClassFailed is the key of LoggingOptions.Levels for requests failed without response.
+const LogClassFailed = 0
+
+const (
+	redacted              = "[REDACTED]"
+	defaultLogMessage     = "http request"
+	defaultLogMaxBodySize = 1024
+)
+
+var defaultRedactHeaders = []string{HeaderAuthorization, HeaderCookie, "Set-Cookie", "Proxy-Authorization"}
+
+type LoggingOptions struct {
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+	// Message defaults to "http request".
+	Message string
+	// Levels are the levels of requests by the class of their status, eg: 4 for 4xx,
+	// and LogClassFailed for the ones failed without response.
+	// The missing classes default to Info for 1xx, 2xx and 3xx, Warn for 4xx, and Error for 5xx and failures.
+	Levels map[int]slog.Level
+	// LogHeaders logs the request and response headers.
+	LogHeaders bool
+	// LogRequestBody logs the request body, truncated to MaxBodySize.
+	LogRequestBody bool
+	// LogResponseBody logs the response body, truncated to MaxBodySize, which reads the whole body into memory.
+	LogResponseBody bool
+	// MaxBodySize defaults to 1024 bytes.
+	MaxBodySize int
+	// RedactHeaders are redacted along with Authorization, Cookie, Set-Cookie and Proxy-Authorization.
+	RedactHeaders []string
+	// RedactFields are the json members and form fields redacted in the logged bodies, matched case-insensitively, eg: password.
+	RedactFields []string
+}
+
+// NewLoggingMiddleware Create a middleware which logs each request with log/slog.
+// It records the method, url, url template, status, duration, attempts, and the sizes of the request and response,
+// the sizes are -1 when they are unknown.
+func NewLoggingMiddleware(opts ...*LoggingOptions) MiddlewareFunc {
+	l := &logging{}
+	if len(opts) > 0 && opts[0] != nil {
+		l.opts = *opts[0]
+	}
+
+	if l.opts.Logger == nil {
+		l.opts.Logger = slog.Default()
+	}
+
+	if l.opts.Message == "" {
+		l.opts.Message = defaultLogMessage
+	}
+
+	if l.opts.MaxBodySize <= 0 {
+		l.opts.MaxBodySize = defaultLogMaxBodySize
+	}
+
+	l.headers = make(map[string]bool)
+	for _, key := range append(defaultRedactHeaders, l.opts.RedactHeaders...) {
+		l.headers[http.CanonicalHeaderKey(key)] = true
+	}
+
+	l.fields = make(map[string]bool)
+	for _, field := range l.opts.RedactFields {
+		l.fields[strings.ToLower(field)] = true
+	}
+
+	return l.handle
+}
+
+type logging struct {
+	opts    LoggingOptions
+	headers map[string]bool
+	fields  map[string]bool
+}
+
+func (l *logging) handle(r Request) (*Response, error) {
+	var (
+		req   = r.Request()
+		start = time.Now()
+		attrs = make([]slog.Attr, 0, 12)
+	)
+
+	attrs = append(attrs, slog.String("method", req.Method), slog.String("url", req.URL.Redacted()))
+	if template := UrlTemplate(req); template != "" {
+		attrs = append(attrs, slog.String("template", template))
+	}
+
+	attrs = append(attrs, slog.Int64("request_size", req.ContentLength))
+
+	if l.opts.LogHeaders {
+		attrs = append(attrs, slog.Any("request_headers", l.redactHeader(req.Header)))
+	}
+
+	if l.opts.LogRequestBody && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			buf, _ := io.ReadAll(body)
+			_ = body.Close()
+			attrs = append(attrs, slog.String("request_body", l.formatBody(buf, req.Header.Get(HeaderContentType))))
+		}
+	}
+
+	resp, err := r.Next()
+
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)), slog.Int("attempts", requestAttempts(req)))
+
+	if err != nil || resp == nil || resp.Response == nil {
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		l.opts.Logger.LogAttrs(req.Context(), l.level(LogClassFailed), l.opts.Message, attrs...)
+
+		return resp, err
+	}
+
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+	size := resp.ContentLength
+	if l.opts.LogResponseBody {
+		if buf, err := resp.ReadBody(); err == nil {
+			size = int64(len(buf))
+			attrs = append(attrs, slog.String("response_body", l.formatBody(buf, resp.Header.Get(HeaderContentType))))
+		}
+	}
+
+	attrs = append(attrs, slog.Int64("response_size", size))
+
+	if l.opts.LogHeaders {
+		attrs = append(attrs, slog.Any("response_headers", l.redactHeader(resp.Header)))
+	}
+
+	l.opts.Logger.LogAttrs(req.Context(), l.level(resp.StatusCode/100), l.opts.Message, attrs...)
+
+	return resp, err
+}
+
+// level returns the level of a status class.
+func (l *logging) level(class int) slog.Level {
+	if level, ok := l.opts.Levels[class]; ok {
+		return level
+	}
+
+	switch class {
+	case 1, 2, 3:
+		return slog.LevelInfo
+	case 4:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// redactHeader returns a copy of the header whose sensitive values are redacted.
+func (l *logging) redactHeader(header http.Header) map[string]string {
+	m := make(map[string]string, len(header))
+	for key, values := range header {
+		if l.headers[http.CanonicalHeaderKey(key)] {
+			m[key] = redacted
+		} else {
+			m[key] = strings.Join(values, ", ")
+		}
+	}
+
+	return m
+}
+
+// formatBody redact the fields of a json or form body, and truncate it to MaxBodySize.
+func (l *logging) formatBody(body []byte, contentType string) string {
+	if len(l.fields) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+
+		switch {
+		case mediaType == ContentTypeFormUrlEncoded:
+			if values, err := url.ParseQuery(string(body)); err == nil {
+				for key := range values {
+					if l.fields[strings.ToLower(key)] {
+						values[key] = []string{redacted}
+					}
+				}
+				body = []byte(values.Encode())
+			}
+		case json.Valid(body):
+			var v interface{}
+			if err := json.Unmarshal(body, &v); err == nil {
+				if buf, err := json.Marshal(l.redactJson(v)); err == nil {
+					body = buf
+				}
+			}
+		}
+	}
+
+	var suffix string
+	if len(body) > l.opts.MaxBodySize {
+		body, suffix = body[:l.opts.MaxBodySize], "...("+strconv.Itoa(len(body)-l.opts.MaxBodySize)+" bytes truncated)"
+	}
+
+	return string(bytes.ToValidUTF8(body, []byte("?"))) + suffix
+}
+
+func (l *logging) redactJson(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if l.fields[strings.ToLower(key)] {
+				val[key] = redacted
+			} else {
+				val[key] = l.redactJson(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = l.redactJson(item)
+		}
+	}
+
+	return v
+}
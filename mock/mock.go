@@ -0,0 +1,454 @@
+// Package mock provides a http.RoundTripper serving registered responders, to unit test code using a client
+// without servers.
+//
+//	transport := mock.NewTransport()
+//	transport.Register(http.MethodGet, "https://api.example.com/users/{id}", mock.NewJsonResponder(200, user))
+//
+//	client := http.NewClient()
+//	client.Transport = transport
+package mock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const paramsKey = "__httpMockParamsKey"
+
+// Params returns the values of the {name} placeholders of the pattern matched by the request.
+func Params(req *http.Request) map[string]string {
+	if params, ok := req.Context().Value(paramsKey).(map[string]string); ok {
+		return params
+	}
+
+	return nil
+}
+
+// Transport is a http.RoundTripper which answers the requests with the responders registered for them.
+type Transport struct {
+	mu          sync.RWMutex
+	routes      []*route
+	noResponder Responder
+	calls       int
+}
+
+type route struct {
+	method    string
+	pattern   string
+	regexp    *regexp.Regexp
+	query     url.Values
+	full      bool
+	raw       bool
+	names     []string
+	responder Responder
+	calls     int
+}
+
+// NewTransport Create a transport without responders.
+func NewTransport() *Transport {
+	return &Transport{}
+}
+
+// Register Register the responder of the requests with the method, an empty method or "*" matches any method.
+// The pattern is an absolute url matched without its query, or a path starting with "/" matched against the path
+// of the requests. It may contain {name} placeholders matching a path segment, and {name...} placeholders matching
+// the rest of the path, whose values are returned by Params. The query of the pattern must be contained in the
+// query of the requests. Registering the same method and pattern again replaces the responder.
+func (t *Transport) Register(method, pattern string, responder Responder) error {
+	r, err := compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	r.method, r.responder = normalizeMethod(method), responder
+
+	t.add(r)
+
+	return nil
+}
+
+// RegisterRegexp Register the responder of the requests with the method, whose urls match the regular expression.
+// The named groups of the expression are returned by Params.
+func (t *Transport) RegisterRegexp(method string, re *regexp.Regexp, responder Responder) {
+	r := &route{method: normalizeMethod(method), pattern: re.String(), regexp: re, full: true, raw: true, responder: responder}
+	for _, name := range re.SubexpNames()[1:] {
+		r.names = append(r.names, name)
+	}
+
+	t.add(r)
+}
+
+// RegisterNoResponder Register the responder of the requests matching no route, which fail by default.
+func (t *Transport) RegisterNoResponder(responder Responder) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.noResponder = responder
+}
+
+func (t *Transport) add(r *route) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, item := range t.routes {
+		if item.method == r.method && item.pattern == r.pattern && item.raw == r.raw {
+			t.routes[i] = r
+			return
+		}
+	}
+
+	t.routes = append(t.routes, r)
+}
+
+// Reset Remove the responders and the calls.
+func (t *Transport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.routes, t.noResponder, t.calls = nil, nil, 0
+}
+
+// RoundTrip Answer the request with the responder of the first route matching it, in the order of registration.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the body is closed once the request is answered, as the http.RoundTripper contract requires
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	t.mu.Lock()
+
+	t.calls++
+
+	var (
+		responder Responder
+		params    map[string]string
+	)
+
+	for _, r := range t.routes {
+		if p, ok := r.match(req); ok {
+			r.calls++
+			responder, params = r.responder, p
+			break
+		}
+	}
+
+	if responder == nil {
+		responder = t.noResponder
+	}
+
+	if responder == nil {
+		err := t.noResponderError(req)
+		t.mu.Unlock()
+		return nil, err
+	}
+
+	t.mu.Unlock()
+
+	if len(params) > 0 {
+		req = req.WithContext(context.WithValue(req.Context(), paramsKey, params))
+	}
+
+	resp, err := responder(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Request == nil {
+		resp.Request = req
+	}
+
+	return resp, nil
+}
+
+// CallCount returns the number of requests sent through the transport, including the unmatched ones.
+func (t *Transport) CallCount() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.calls
+}
+
+// Calls returns the number of requests answered by the responder registered with the method and the pattern,
+// or -1 if there is no such responder.
+func (t *Transport) Calls(method, pattern string) int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	method = normalizeMethod(method)
+	for _, r := range t.routes {
+		if r.method == method && r.pattern == pattern {
+			return r.calls
+		}
+	}
+
+	return -1
+}
+
+// AssertCalls Assert the responder registered with the method and the pattern was called n times.
+func (t *Transport) AssertCalls(tb testing.TB, method, pattern string, n int) bool {
+	tb.Helper()
+
+	if calls := t.Calls(method, pattern); calls != n {
+		tb.Errorf("mock: %s %s called %d times, want %d", displayMethod(normalizeMethod(method)), pattern, max(calls, 0), n)
+		return false
+	}
+
+	return true
+}
+
+// AssertAllCalled Assert each registered responder was called at least once.
+func (t *Transport) AssertAllCalled(tb testing.TB) bool {
+	tb.Helper()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	ok := true
+	for _, r := range t.routes {
+		if r.calls == 0 {
+			tb.Errorf("mock: %s %s was never called", displayMethod(r.method), r.pattern)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// NoResponderError is returned for a request matching no route, with the routes closest to it.
+type NoResponderError struct {
+	Method string
+	URL    string
+	// Candidates are the closest routes, eg: "GET /users/{id}".
+	Candidates []string
+}
+
+func (e *NoResponderError) Error() string {
+	msg := fmt.Sprintf("mock: no responder found for %s %s", e.Method, e.URL)
+	if len(e.Candidates) > 0 {
+		msg += ", closest: " + strings.Join(e.Candidates, ", ")
+	}
+
+	return msg
+}
+
+const maxCandidates = 3
+
+var placeholders = regexp.MustCompile(`\{[^}]*\}`)
+
+// noResponderError returns the error of the request with the routes ranked by their edit distance to it,
+// the placeholders of the patterns are ignored.
+func (t *Transport) noResponderError(req *http.Request) error {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	candidates := make([]candidate, 0, len(t.routes))
+	for _, r := range t.routes {
+		target := req.URL.String()
+		if !r.full {
+			target = req.URL.RequestURI()
+		}
+
+		pattern := r.pattern
+		if !r.raw {
+			pattern = placeholders.ReplaceAllString(pattern, "")
+		}
+
+		distance := levenshtein(target, pattern)
+		if r.method != "" && r.method != req.Method {
+			distance += len(req.Method)
+		}
+
+		candidates = append(candidates, candidate{name: displayMethod(r.method) + " " + r.pattern, distance: distance})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	err := &NoResponderError{Method: req.Method, URL: req.URL.String()}
+	for i := 0; i < len(candidates) && i < maxCandidates; i++ {
+		err.Candidates = append(err.Candidates, candidates[i].name)
+	}
+
+	return err
+}
+
+// compile convert a pattern into a route.
+func compile(pattern string) (*route, error) {
+	r := &route{pattern: pattern}
+
+	path := pattern
+	if i := strings.IndexByte(pattern, '?'); i >= 0 {
+		query, err := url.ParseQuery(pattern[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("mock: invalid query in pattern %q: %w", pattern, err)
+		}
+		path, r.query = pattern[:i], query
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		u, err := url.Parse(path)
+		if err != nil || !u.IsAbs() {
+			return nil, fmt.Errorf("mock: pattern %q is neither an absolute url nor a path", pattern)
+		}
+		r.full = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			sb.WriteString(regexp.QuoteMeta(path))
+			break
+		}
+
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("mock: unclosed placeholder in pattern %q", pattern)
+		}
+		end += start
+
+		name := path[start+1 : end]
+		sb.WriteString(regexp.QuoteMeta(path[:start]))
+
+		if strings.HasSuffix(name, "...") {
+			name = strings.TrimSuffix(name, "...")
+			sb.WriteString("(.*)")
+		} else {
+			sb.WriteString("([^/]+)")
+		}
+
+		r.names = append(r.names, name)
+		path = path[end+1:]
+	}
+
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, err
+	}
+
+	r.regexp = re
+
+	return r, nil
+}
+
+// match reports whether the route matches the request, with the params of the placeholders.
+func (r *route) match(req *http.Request) (map[string]string, bool) {
+	if r.method != "" && r.method != req.Method {
+		return nil, false
+	}
+
+	var target string
+	switch {
+	case r.raw:
+		target = req.URL.String()
+	case r.full:
+		u := *req.URL
+		u.RawQuery, u.Fragment = "", ""
+		target = u.String()
+	default:
+		target = req.URL.Path
+	}
+
+	matches := r.regexp.FindStringSubmatch(target)
+	if matches == nil {
+		return nil, false
+	}
+
+	if len(r.query) > 0 {
+		query := req.URL.Query()
+		for key, values := range r.query {
+			if !containsAll(query[key], values) {
+				return nil, false
+			}
+		}
+	}
+
+	var params map[string]string
+	for i, name := range r.names {
+		if name == "" {
+			continue
+		}
+
+		if params == nil {
+			params = make(map[string]string, len(r.names))
+		}
+
+		value := matches[i+1]
+		if v, err := url.PathUnescape(value); err == nil {
+			value = v
+		}
+		params[name] = value
+	}
+
+	return params, true
+}
+
+func containsAll(values, wants []string) bool {
+	for _, want := range wants {
+		found := false
+		for _, value := range values {
+			if value == want {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func normalizeMethod(method string) string {
+	if method == "*" {
+		return ""
+	}
+
+	return strings.ToUpper(method)
+}
+
+func displayMethod(method string) string {
+	if method == "" {
+		return "*"
+	}
+
+	return method
+}
+
+// levenshtein returns the edit distance of two strings.
+func levenshtein(a, b string) int {
+	prev, curr := make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,163 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dobyte/http"
+	"github.com/dobyte/http/mock"
+	"io"
+	stdhttp "net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransport_Register(t *testing.T) {
+	transport := mock.NewTransport()
+
+	transport.Register(http.MethodGet, "https://api.example.com/users/{id}", func(req *stdhttp.Request) (*stdhttp.Response, error) {
+		return mock.NewJsonResponder(200, map[string]string{"id": mock.Params(req)["id"]})(req)
+	})
+	transport.Register(http.MethodGet, "/files/{path...}?download=1", func(req *stdhttp.Request) (*stdhttp.Response, error) {
+		return mock.NewStringResponder(200, mock.Params(req)["path"])(req)
+	})
+	transport.RegisterRegexp("*", regexp.MustCompile(`/orders/(?P<order>\d+)$`), mock.NewStringResponder(204, ""))
+	transport.Register(http.MethodPost, "https://api.example.com/users", mock.NewSequenceResponder(
+		mock.NewStringResponder(500, "busy"),
+		mock.NewStringResponder(201, "created").Header("Location", "/users/1"),
+	))
+
+	client := http.NewClient()
+	client.Transport = transport
+
+	var user map[string]string
+
+	resp, err := client.Get("https://api.example.com/users/a%20b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = resp.ScanBody(&user); err != nil || user["id"] != "a b" {
+		t.Errorf("Get() = %v, %v", user, err)
+	}
+
+	if resp, err = client.Get("https://cdn.example.com/files/a/b.txt?download=1&v=2", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := resp.ReadBody(); string(body) != "a/b.txt" {
+		t.Errorf("Get() file = %s", body)
+	}
+
+	if resp, err = client.Delete("https://api.example.com/orders/42", nil); err != nil || resp.StatusCode != 204 {
+		t.Errorf("Delete() = %v, %v", resp, err)
+	}
+
+	for _, want := range []int{500, 201, 201} {
+		if resp, err = client.Post("https://api.example.com/users", nil); err != nil || resp.StatusCode != want {
+			t.Fatalf("Post() = %v, %v, want status %d", resp, err, want)
+		}
+	}
+
+	if resp.GetHeader("Location") != "/users/1" {
+		t.Errorf("Post() location = %s", resp.GetHeader("Location"))
+	}
+
+	transport.AssertCalls(t, http.MethodPost, "https://api.example.com/users", 3)
+	transport.AssertAllCalled(t)
+
+	if transport.CallCount() != 6 {
+		t.Errorf("CallCount() = %d, want 6", transport.CallCount())
+	}
+}
+
+func TestTransport_NoResponder(t *testing.T) {
+	transport := mock.NewTransport()
+	transport.Register(http.MethodGet, "/users/{id}", mock.NewStringResponder(200, "user"))
+	transport.Register(http.MethodGet, "/orders", mock.NewStringResponder(200, "orders"))
+	transport.Register(http.MethodPost, "/user", mock.NewStringResponder(200, "user"))
+	transport.Register(http.MethodGet, "https://other.example.com/status", mock.NewStringResponder(200, "ok"))
+
+	client := http.NewClient()
+	client.Transport = transport
+
+	_, err := client.Get("https://api.example.com/user", nil)
+
+	var e *mock.NoResponderError
+	if !errors.As(err, &e) {
+		t.Fatalf("Get() = %v, want a NoResponderError", err)
+	}
+
+	if want := "GET /users/{id}, POST /user, GET /orders"; strings.Join(e.Candidates, ", ") != want {
+		t.Errorf("Candidates = %v, want %s", e.Candidates, want)
+	}
+
+	transport.RegisterNoResponder(mock.NewStringResponder(404, ""))
+
+	if resp, err := client.Get("https://api.example.com/user", nil); err != nil || resp.StatusCode != 404 {
+		t.Errorf("Get() with a no responder = %v, %v", resp, err)
+	}
+}
+
+type closeCounter struct {
+	io.Reader
+	closed int
+}
+
+func (c *closeCounter) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestTransport_RoundTrip_CloseBody(t *testing.T) {
+	transport := mock.NewTransport()
+	transport.Register(http.MethodPost, "/users", func(req *stdhttp.Request) (*stdhttp.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		return mock.NewStringResponder(201, string(body))(req)
+	})
+
+	for _, url := range []string{"https://api.example.com/users", "https://api.example.com/orders"} {
+		body := &closeCounter{Reader: strings.NewReader("alice")}
+
+		req, _ := stdhttp.NewRequest(http.MethodPost, url, body)
+		if resp, err := transport.RoundTrip(req); err == nil {
+			resp.Body.Close()
+		}
+
+		if body.closed != 1 {
+			t.Errorf("RoundTrip(%s) closed the body %d times, want 1", url, body.closed)
+		}
+	}
+}
+
+func TestResponder(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "user.json")
+	os.WriteFile(filename, []byte(`{"name":"fuxiao"}`), 0644)
+
+	transport := mock.NewTransport()
+	transport.Register("", "/file", mock.NewFileResponder(200, filename))
+	transport.Register("", "/slow", mock.NewStringResponder(200, "slow").Delay(time.Second))
+	transport.Register("", "/error", mock.NewErrorResponder(errors.New("connection reset")))
+
+	client := http.NewClient()
+	client.Transport = transport
+
+	resp, err := client.Get("http://example.com/file", nil)
+	if err != nil || resp.GetHeader(http.HeaderContentType) != "application/json" {
+		t.Errorf("Get() file = %v, %v", resp, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err = client.Get("http://example.com/slow", nil, &http.RequestOptions{Context: ctx}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() slow = %v, want deadline exceeded", err)
+	}
+
+	if _, err = client.Get("http://example.com/error", nil); err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("Get() error = %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Responder answers a request.
+type Responder func(req *http.Request) (*http.Response, error)
+
+// NewResponse Create a response to the request with the status and the body.
+func NewResponse(req *http.Request, status int, body []byte, header http.Header) *http.Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// NewBytesResponder Create a responder answering with the status and the body.
+func NewBytesResponder(status int, body []byte) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		return NewResponse(req, status, body, nil), nil
+	}
+}
+
+// NewStringResponder Create a responder answering with the status and the body.
+func NewStringResponder(status int, body string) Responder {
+	return NewBytesResponder(status, []byte(body))
+}
+
+// NewJsonResponder Create a responder answering with the status and v encoded as json.
+// The responder fails the requests if v can't be encoded.
+func NewJsonResponder(status int, v interface{}) Responder {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return NewErrorResponder(err)
+	}
+
+	return NewBytesResponder(status, body).Header("Content-Type", "application/json")
+}
+
+// NewFileResponder Create a responder answering with the status and the content of the file,
+// which is read for each request, the Content-Type is guessed from its extension.
+func NewFileResponder(status int, filename string) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		body, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := NewResponse(req, status, body, nil)
+		if contentType := mime.TypeByExtension(filepath.Ext(filename)); contentType != "" {
+			resp.Header.Set("Content-Type", contentType)
+		}
+
+		return resp, nil
+	}
+}
+
+// NewErrorResponder Create a responder failing the requests with the error, eg: a *net.OpError.
+func NewErrorResponder(err error) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		return nil, err
+	}
+}
+
+// NewSequenceResponder Create a responder answering with the responders in turn, the last one answers
+// the requests after the others.
+func NewSequenceResponder(responders ...Responder) Responder {
+	if len(responders) == 0 {
+		return NewErrorResponder(errors.New("mock: empty sequence of responders"))
+	}
+
+	var (
+		mu    sync.Mutex
+		index int
+	)
+
+	return func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		responder := responders[index]
+		if index < len(responders)-1 {
+			index++
+		}
+		mu.Unlock()
+
+		return responder(req)
+	}
+}
+
+// Delay returns a responder answering after the delay, or failing with the error of the context
+// of the request if it's done before.
+func (r Responder) Delay(delay time.Duration) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			return r(req)
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// Header returns a responder setting the header on the responses.
+func (r Responder) Header(key, value string) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := r(req)
+		if err == nil {
+			resp.Header.Set(key, value)
+		}
+
+		return resp, err
+	}
+}
@@ -0,0 +1,294 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/dobyte/http/internal/codec"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderContentRange = "Content-Range"
+	HeaderContentID    = "Content-ID"
+
+	ContentTypeHttp                = "application/http"
+	ContentTypeMultipartMixed      = "multipart/mixed"
+	ContentTypeMultipartByteRanges = "multipart/byteranges"
+)
+
+var (
+	ErrNotMultipart  = errors.New("http: response is not multipart")
+	ErrPartDiscarded = errors.New("http: part body has been discarded")
+)
+
+type Parts struct {
+	response  *Response
+	reader    *multipart.Reader
+	mediaType string
+	current   *Part
+}
+
+type Part struct {
+	Header textproto.MIMEHeader
+
+	codecs *codec.Registry
+	reader io.Reader
+	body   []byte
+	err    error
+	read   bool
+}
+
+type ContentRange struct {
+	Start int64 // the first byte offset of the part, inclusive.
+	End   int64 // the last byte offset of the part, inclusive.
+	Size  int64 // the complete length of the resource, -1 if unknown.
+}
+
+// Parts returns an iterator over the parts of a multipart response.
+func (r *Response) Parts() (*Parts, error) {
+	mediaType, params, err := mime.ParseMediaType(r.GetHeader(HeaderContentType))
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, ErrNotMultipart
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("http: multipart response has no boundary")
+	}
+
+	var body io.Reader
+	if r.bodyRead {
+		if r.err != nil {
+			return nil, r.err
+		}
+		body = bytes.NewReader(r.body)
+	} else {
+		body = r.Response.Body
+	}
+
+	return &Parts{
+		response:  r,
+		reader:    multipart.NewReader(body, boundary),
+		mediaType: mediaType,
+	}, nil
+}
+
+// MediaType Returns the media type of the multipart response, eg: multipart/mixed.
+func (p *Parts) MediaType() string {
+	return p.mediaType
+}
+
+// Next Returns the next part of the response, or io.EOF when there are no more parts.
+// The body of the previous part is discarded unless it was read entirely.
+func (p *Parts) Next() (*Part, error) {
+	if p.current != nil {
+		p.current.discard()
+	}
+
+	raw, err := p.reader.NextRawPart()
+	if err != nil {
+		if err == io.EOF {
+			_ = p.Close()
+		}
+		return nil, err
+	}
+
+	p.current = &Part{Header: raw.Header, codecs: p.response.codecs, reader: raw}
+
+	return p.current, nil
+}
+
+// All Reads and returns all remaining parts, each part's body is buffered.
+func (p *Parts) All() ([]*Part, error) {
+	parts := make([]*Part, 0)
+
+	for {
+		part, err := p.Next()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = part.ReadBody(); err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, part)
+	}
+}
+
+// Responses Decodes all remaining parts of a batch response into responses.
+// Each part must carry an application/http message.
+func (p *Parts) Responses() ([]*Response, error) {
+	parts, err := p.All()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*Response, 0, len(parts))
+	for _, part := range parts {
+		resp, err := part.ReadResponse(p.response.Request)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, resp)
+	}
+
+	return responses, nil
+}
+
+// Close closes the underlying response.
+func (p *Parts) Close() error {
+	return p.response.Close()
+}
+
+// Read reads the body of the part as a stream.
+func (p *Part) Read(b []byte) (int, error) {
+	if p.read {
+		return 0, io.EOF
+	}
+
+	return p.reader.Read(b)
+}
+
+// ReadBody retrieves and returns the part content as []byte.
+func (p *Part) ReadBody() ([]byte, error) {
+	if !p.read {
+		p.body, p.err = io.ReadAll(p.reader)
+		p.read = true
+	}
+
+	return p.body[:], p.err
+}
+
+// ScanBody convert the part content into a complex data structure by its Content-Type.
+func (p *Part) ScanBody(pointer interface{}) error {
+	if pointer == nil {
+		return nil
+	}
+
+	buf, err := p.ReadBody()
+	if err != nil {
+		return err
+	}
+
+	return decode(p.codecs, buf, pointer, p.ContentType())
+}
+
+// GetHeader Retrieve header's value from the part.
+func (p *Part) GetHeader(key string) string {
+	return p.Header.Get(key)
+}
+
+// ContentType Returns the Content-Type of the part.
+func (p *Part) ContentType() string {
+	return p.Header.Get(HeaderContentType)
+}
+
+// ContentID Returns the Content-ID of the part, which batch APIs use to correlate sub-requests.
+func (p *Part) ContentID() string {
+	return p.Header.Get(HeaderContentID)
+}
+
+// ContentRange Parses the Content-Range header of a multipart/byteranges part.
+func (p *Part) ContentRange() (*ContentRange, error) {
+	return parseContentRange(p.Header.Get(HeaderContentRange))
+}
+
+// WriteRangeTo Writes the part content to w at the offset declared by its Content-Range.
+func (p *Part) WriteRangeTo(w io.WriterAt) (int64, error) {
+	cr, err := p.ContentRange()
+	if err != nil {
+		return 0, err
+	}
+
+	buf, err := p.ReadBody()
+	if err != nil {
+		return 0, err
+	}
+
+	if int64(len(buf)) != cr.End-cr.Start+1 {
+		return 0, fmt.Errorf("http: part length %d does not match content range %d-%d", len(buf), cr.Start, cr.End)
+	}
+
+	n, err := w.WriteAt(buf, cr.Start)
+
+	return int64(n), err
+}
+
+// ReadResponse Decodes an application/http part into a response.
+// The request is attached to the response and may be nil.
+func (p *Part) ReadResponse(req *http.Request) (*Response, error) {
+	buf, err := p.ReadBody()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf)), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{Response: resp, Request: req, codecs: p.codecs}, nil
+}
+
+// discard drains the rest of the part so that the next part can be read.
+func (p *Part) discard() {
+	if !p.read {
+		_, _ = io.Copy(io.Discard, p.reader)
+		p.read, p.err = true, ErrPartDiscarded
+	}
+}
+
+// parseContentRange parse a Content-Range value, eg: bytes 0-499/1234.
+func parseContentRange(value string) (*ContentRange, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "bytes ") {
+		return nil, fmt.Errorf("http: invalid content range %q", value)
+	}
+
+	spec, size, ok := strings.Cut(strings.TrimSpace(value[len("bytes "):]), "/")
+	if !ok {
+		return nil, fmt.Errorf("http: invalid content range %q", value)
+	}
+
+	start, end, ok := strings.Cut(spec, "-")
+	if !ok {
+		return nil, fmt.Errorf("http: invalid content range %q", value)
+	}
+
+	var (
+		err error
+		cr  = &ContentRange{Size: -1}
+	)
+
+	if cr.Start, err = strconv.ParseInt(start, 10, 64); err != nil {
+		return nil, fmt.Errorf("http: invalid content range %q", value)
+	}
+
+	if cr.End, err = strconv.ParseInt(end, 10, 64); err != nil || cr.End < cr.Start {
+		return nil, fmt.Errorf("http: invalid content range %q", value)
+	}
+
+	if size != "*" {
+		if cr.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return nil, fmt.Errorf("http: invalid content range %q", value)
+		}
+	}
+
+	return cr, nil
+}
@@ -0,0 +1,367 @@
+package http
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/dobyte/http/internal/codec"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentTypeProblemJson = "application/problem+json"
+	ContentTypeProblemXml  = "application/problem+xml"
+
+	problemNamespace = "urn:ietf:rfc:7807"
+	problemBlankType = "about:blank"
+)
+
+// Problem is an RFC 9457 problem details object, which Response.Problem decodes from an error response.
+// When status errors are enabled, it is returned instead of *StatusError for a problem response and wraps the *StatusError.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+
+	err *StatusError
+}
+
+func (p *Problem) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("http: problem ")
+	if p.Status != 0 {
+		sb.WriteString(strconv.Itoa(p.Status) + " ")
+	}
+	sb.WriteString(p.Type)
+
+	if p.Title != "" {
+		sb.WriteString(": " + p.Title)
+	}
+
+	if p.Detail != "" {
+		sb.WriteString(": " + p.Detail)
+	}
+
+	return sb.String()
+}
+
+// Unwrap Returns the status error of the response.
+func (p *Problem) Unwrap() error {
+	if p.err == nil {
+		return nil
+	}
+
+	return p.err
+}
+
+// MarshalJSON encode the problem with the extension members on the top level.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		m[key] = value
+	}
+
+	for key, value := range map[string]string{"type": p.Type, "title": p.Title, "detail": p.Detail, "instance": p.Instance} {
+		if value != "" {
+			m[key] = value
+		}
+	}
+
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decode the problem, unknown members are kept in the extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	*p = Problem{Type: problemBlankType, err: p.err}
+
+	for key, raw := range m {
+		var err error
+
+		switch key {
+		case "type":
+			err = json.Unmarshal(raw, &p.Type)
+		case "title":
+			err = json.Unmarshal(raw, &p.Title)
+		case "status":
+			err = json.Unmarshal(raw, &p.Status)
+		case "detail":
+			err = json.Unmarshal(raw, &p.Detail)
+		case "instance":
+			err = json.Unmarshal(raw, &p.Instance)
+		default:
+			var value interface{}
+			if err = json.Unmarshal(raw, &value); err == nil {
+				if p.Extensions == nil {
+					p.Extensions = make(map[string]interface{})
+				}
+				p.Extensions[key] = value
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("http: invalid problem member %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// MarshalXML encode the problem as an application/problem+xml document.
+func (p Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Space: problemNamespace, Local: "problem"}
+	start.Attr = nil
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	elements := []struct {
+		name  string
+		value string
+	}{
+		{"type", p.Type},
+		{"title", p.Title},
+		{"status", ""},
+		{"detail", p.Detail},
+		{"instance", p.Instance},
+	}
+
+	if p.Status != 0 {
+		elements[2].value = strconv.Itoa(p.Status)
+	}
+
+	for _, el := range elements {
+		if el.value == "" {
+			continue
+		}
+
+		if err := e.EncodeElement(el.value, xml.StartElement{Name: xml.Name{Local: el.name}}); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(p.Extensions))
+	for key := range p.Extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := encodeProblemValue(e, key, reflect.ValueOf(p.Extensions[key])); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// encodeProblemValue encode an extension member as RFC 9457 Appendix B does: an array is an element of <i> items,
+// an object is an element of its members sorted by name, and a nil value is skipped.
+func encodeProblemValue(e *xml.Encoder, name string, rv reflect.Value) error {
+	for rv.Kind() == reflect.Interface || rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return e.EncodeElement(rv.Interface(), start)
+		}
+
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			if err := encodeProblemValue(e, "i", rv.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		return e.EncodeToken(start.End())
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("http: unsupported problem member %q of type %s", name, rv.Type())
+		}
+
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := encodeProblemValue(e, key.String(), rv.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+
+		return e.EncodeToken(start.End())
+	}
+
+	return e.EncodeElement(rv.Interface(), start)
+}
+
+// UnmarshalXML decode an application/problem+xml document, unknown elements are kept in the extensions,
+// as text, as a []interface{} for an element of <i> items, or as a map[string]interface{} for other elements.
+func (p *Problem) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = Problem{Type: problemBlankType, err: p.err}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			member, err := decodeProblemValue(d)
+			if err != nil {
+				return err
+			}
+
+			value, _ := member.(string)
+
+			switch el.Name.Local {
+			case "type":
+				p.Type = value
+			case "title":
+				p.Title = value
+			case "status":
+				if p.Status, err = strconv.Atoi(value); err != nil {
+					return fmt.Errorf("http: invalid problem member %q: %w", "status", err)
+				}
+			case "detail":
+				p.Detail = value
+			case "instance":
+				p.Instance = value
+			default:
+				if p.Extensions == nil {
+					p.Extensions = make(map[string]interface{})
+				}
+				p.Extensions[el.Name.Local] = member
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeProblemValue decode the content of an extension member, whose start element has been read.
+func decodeProblemValue(d *xml.Decoder) (interface{}, error) {
+	type member struct {
+		name  string
+		value interface{}
+	}
+
+	var (
+		text     strings.Builder
+		children []member
+		items    = true
+	)
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch el := token.(type) {
+		case xml.CharData:
+			text.Write(el)
+		case xml.StartElement:
+			value, err := decodeProblemValue(d)
+			if err != nil {
+				return nil, err
+			}
+
+			children = append(children, member{name: el.Name.Local, value: value})
+			items = items && el.Name.Local == "i"
+		case xml.EndElement:
+			if len(children) == 0 {
+				return strings.TrimSpace(text.String()), nil
+			}
+
+			if items {
+				values := make([]interface{}, 0, len(children))
+				for _, child := range children {
+					values = append(values, child.value)
+				}
+
+				return values, nil
+			}
+
+			members := make(map[string]interface{}, len(children))
+			for _, child := range children {
+				members[child.name] = child.value
+			}
+
+			return members, nil
+		}
+	}
+}
+
+// Problem decode the body of a non-2xx problem details response, whether status errors are enabled or not.
+// It returns nil if the response isn't an application/problem+json or application/problem+xml error.
+func (r *Response) Problem() *Problem {
+	if r == nil || r.Response == nil || r.StatusCode >= 200 && r.StatusCode < 300 {
+		return nil
+	}
+
+	switch mediaType := codec.MediaType(r.GetHeader(HeaderContentType)); mediaType {
+	case ContentTypeProblemJson, ContentTypeProblemXml:
+		buf, err := r.ReadBody()
+		if err != nil || len(buf) == 0 {
+			return nil
+		}
+
+		p := &Problem{}
+		if err = r.ScanBody(p, mediaType); err != nil {
+			return nil
+		}
+
+		if p.Status == 0 {
+			p.Status = r.StatusCode
+		}
+
+		return p
+	}
+
+	return nil
+}
+
+// problem returns the problem of the response wrapping the status error, nil if it's not a problem.
+func (e *StatusError) problem() *Problem {
+	p := e.Response.Problem()
+	if p != nil {
+		p.err = e
+	}
+
+	return p
+}
@@ -0,0 +1,23 @@
+package http
+
+import "github.com/dobyte/http/internal/query"
+
+const (
+	ArrayFormatRepeat   = query.ArrayRepeat
+	ArrayFormatBrackets = query.ArrayBrackets
+	ArrayFormatIndices  = query.ArrayIndices
+	ArrayFormatComma    = query.ArrayComma
+
+	NestedFormatBrackets = query.NestedBrackets
+	NestedFormatDots     = query.NestedDots
+
+	TimeFormatUnix      = query.TimeFormatUnix
+	TimeFormatUnixMilli = query.TimeFormatUnixMilli
+)
+
+type (
+	ArrayFormat  = query.ArrayFormat
+	NestedFormat = query.NestedFormat
+	// QueryEncoder encodes structs, maps and url.Values into sorted query strings and url encoded forms.
+	QueryEncoder = query.Encoder
+)
@@ -11,8 +11,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"encoding/xml"
-	"github.com/dobyte/http/internal"
 	"net/http"
 	"regexp"
 	"strings"
@@ -35,8 +33,25 @@ type request struct {
 }
 
 type RequestOptions struct {
+	// Headers replace the common headers with the same canonical key.
 	Headers map[string]string
+	// Header is multi-valued headers, which replace the common headers and Headers with the same canonical key.
+	Header http.Header
+	// Cookies are sent with the request, replacing the common cookies with the same name.
 	Cookies map[string]string
+	// StatusError returns a non-2xx response as a *StatusError.
+	StatusError bool
+	// Error is filled with the body of a non-2xx response, and enables StatusError.
+	Error interface{}
+	// Context overrides the context of the client for the request.
+	Context context.Context
+	// Query is a struct, a map or url.Values merged into the query of the url,
+	// it replaces the parameters with the same key in the url or the base url.
+	Query interface{}
+	// PathParams is a map or a struct with path tags, eg: `path:"org"`.
+	// It replaces the {name} and {name...} placeholders in the url path, which is kept as the url template.
+	// A placeholder without a param is an error, the braces around other text, eg: {"a":1}, are kept as they are.
+	PathParams interface{}
 }
 
 func newRequest(client *Client) *request {
@@ -56,26 +71,93 @@ func (r *request) request(method, url string, data interface{}, opts ...*Request
 // build a http request.
 func (r *request) prepare(method, url string, data interface{}, opts ...*RequestOptions) (req *http.Request, err error) {
 	var (
-		buf     []byte
-		body    = bytes.NewBuffer(nil)
-		headers = r.client.GetHeaders()
-		cookies = r.client.GetCookies()
+		buf      []byte
+		body     = bytes.NewBuffer(nil)
+		headers  = r.client.cloneHeaders()
+		cookies  = make(map[string]string)
+		ctx      = r.client.ctx
+		queries  []interface{}
+		bound    *binding
+		params   map[string]string
+		template = url
 	)
 
-	method, url = strings.ToUpper(method), r.makeUrl(url)
+	method = strings.ToUpper(method)
+
+	if bound, err = bind(data, method); err != nil {
+		return
+	}
+
+	if bound != nil {
+		data, queries = nil, append(queries, bound.query)
+
+		if len(bound.path) > 0 {
+			params = bound.path
+		}
+
+		for key, value := range bound.headers {
+			headers.Set(key, value)
+		}
+
+		for key, value := range bound.cookies {
+			cookies[key] = value
+		}
+
+		// an explicit Content-Type wins over the one of the body fields
+		if bound.body != nil {
+			data = bound.body
+			if headers.Get(HeaderContentType) == "" {
+				headers.Set(HeaderContentType, bound.contentType)
+			}
+		}
+	}
 
 	if len(opts) > 0 && opts[0] != nil {
+		var pathParams map[string]string
+		if pathParams, err = parsePathParams(opts[0].PathParams); err != nil {
+			return
+		}
+
+		if pathParams != nil && params == nil {
+			params = make(map[string]string, len(pathParams))
+		}
+
+		for key, value := range pathParams {
+			params[key] = value
+		}
+
+		if opts[0].Query != nil {
+			queries = append(queries, opts[0].Query)
+		}
+
 		for key, value := range opts[0].Headers {
-			headers[key] = value
+			headers.Set(key, value)
+		}
+
+		for key, values := range opts[0].Header {
+			headers[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
 		}
 
 		for key, value := range opts[0].Cookies {
 			cookies[key] = value
 		}
+
+		r.statusError, r.errorResult = opts[0].StatusError, opts[0].Error
+
+		if opts[0].Context != nil {
+			ctx = opts[0].Context
+		}
+	}
+
+	if url, err = expandTemplate(url, params); err != nil {
+		return
+	}
+
+	if url, err = r.makeUrl(url); err != nil {
+		return
 	}
 
-	switch contentType := headers[HeaderContentType]; contentType {
-	case ContentTypeJson, ContentTypeXml, ContentTypeFormUrlEncoded:
+	if c, ok := r.client.GetCodec(headers.Get(HeaderContentType)); ok {
 		switch v := data.(type) {
 		case nil:
 			// ignore
@@ -84,24 +166,14 @@ func (r *request) prepare(method, url string, data interface{}, opts ...*Request
 		case []byte:
 			buf = v[:]
 		default:
-			switch contentType {
-			case ContentTypeJson:
-				buf, err = json.Marshal(data)
-				if err != nil {
-					return
-				}
-			case ContentTypeXml:
-				buf, err = xml.Marshal(data)
-				if err != nil {
-					return
-				}
-			case ContentTypeFormUrlEncoded:
-				buf = []byte(internal.BuildParams(data))
+			buf, err = c.Marshal(data)
+			if err != nil {
+				return
 			}
 		}
 
 		body.Write(buf)
-	default:
+	} else {
 		switch v := data.(type) {
 		case nil:
 			// ignore
@@ -112,17 +184,21 @@ func (r *request) prepare(method, url string, data interface{}, opts ...*Request
 		default:
 			switch method {
 			case MethodGet, MethodPost:
-				buf = []byte(internal.BuildParams(data))
+				var params string
+				if params, err = r.client.queryEncoder.Encode(data); err != nil {
+					return
+				}
+				buf = []byte(params)
 			}
 		}
 
 		if len(buf) > 0 {
 			if (buf[0] == '[' || buf[0] == '{') && json.Valid(buf) {
-				headers[HeaderContentType] = ContentTypeJson
+				headers.Set(HeaderContentType, ContentTypeJson)
 				body.Write(buf)
 			} else if matched, _ := regexp.Match(`^[\w\[\]]+=.+`, buf); matched {
 				if method != MethodGet {
-					headers[HeaderContentType] = ContentTypeFormUrlEncoded
+					headers.Set(HeaderContentType, ContentTypeFormUrlEncoded)
 					body.Write(buf)
 				}
 			} else {
@@ -141,32 +217,33 @@ func (r *request) prepare(method, url string, data interface{}, opts ...*Request
 		}
 	}
 
+	for _, query := range queries {
+		var values map[string][]string
+		if values, err = r.client.queryEncoder.Values(query); err != nil {
+			return
+		}
+
+		if url, err = mergeQuery(url, values, true); err != nil {
+			return
+		}
+	}
+
 	req, err = http.NewRequest(method, url, body)
 	if err != nil {
 		return
 	}
 
-	if r.client.ctx != nil {
-		req = req.WithContext(r.client.ctx)
-	} else {
-		req = req.WithContext(context.Background())
+	if ctx == nil {
+		ctx = context.Background()
 	}
 
-	for key, value := range headers {
-		switch key {
-		case HeaderCookie:
-			// ignore
-		default:
-			req.Header.Set(key, value)
-		}
-	}
+	req = req.WithContext(context.WithValue(ctx, templateKey, template))
 
-	if len(cookies) > 0 {
-		slice := make([]string, 0, len(cookies))
-		for key, value := range r.client.cookies {
-			slice = append(slice, key+"="+value)
-		}
-		req.Header.Set(HeaderCookie, strings.Join(slice, ";"))
+	headers.Del(HeaderCookie)
+	req.Header = headers
+
+	if cookie := cookieHeader(r.client.cookies.match(req.URL), cookies); cookie != "" {
+		req.Header.Set(HeaderCookie, cookie)
 	}
 
 	if host := req.Header.Get(HeaderHost); host != "" {
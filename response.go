@@ -8,7 +8,8 @@
 package http
 
 import (
-	"github.com/dobyte/http/internal/xconv"
+	"fmt"
+	"github.com/dobyte/http/internal/codec"
 	"io"
 	"net/http"
 	"sync"
@@ -18,8 +19,11 @@ type Response struct {
 	*http.Response
 	Request *http.Request
 
+	codecs *codec.Registry
+
 	err      error
 	body     []byte
+	bodyRead bool
 	bodyOnce sync.Once
 
 	cookies     map[string]string
@@ -37,6 +41,7 @@ func (r *Response) ReadBody() ([]byte, error) {
 
 	r.bodyOnce.Do(func() {
 		r.body, r.err = io.ReadAll(r.Response.Body)
+		r.bodyRead = true
 		_ = r.Close()
 	})
 
@@ -44,7 +49,8 @@ func (r *Response) ReadBody() ([]byte, error) {
 }
 
 // ScanBody convert the response into a complex data structure.
-func (r *Response) ScanBody(pointer interface{}) error {
+// The decoder is chosen by the Content-Type of the response, unless contentType is given.
+func (r *Response) ScanBody(pointer interface{}, contentType ...string) error {
 	if pointer == nil {
 		return nil
 	}
@@ -54,7 +60,12 @@ func (r *Response) ScanBody(pointer interface{}) error {
 		return err
 	}
 
-	return xconv.Scan(buf, pointer)
+	ct := r.GetHeader(HeaderContentType)
+	if len(contentType) > 0 && contentType[0] != "" {
+		ct = contentType[0]
+	}
+
+	return decode(r.codecs, buf, pointer, ct)
 }
 
 // Close closes the response when it will never be used.
@@ -106,3 +117,38 @@ func (r *Response) GetCookies() map[string]string {
 
 	return r.cookies
 }
+
+const decodeErrorSnippetSize = 256
+
+// DecodeError is returned when the body can not be decoded with its content type.
+type DecodeError struct {
+	ContentType string
+	Snippet     string
+	Err         error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("http: decode %q body failed: %v, body: %q", e.ContentType, e.Err, e.Snippet)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decode the body into pointer by the codec of the content type.
+func decode(codecs *codec.Registry, buf []byte, pointer interface{}, contentType string) error {
+	if err := codecs.Decode(buf, pointer, contentType); err != nil {
+		return &DecodeError{ContentType: contentType, Snippet: snippet(buf, decodeErrorSnippetSize), Err: err}
+	}
+
+	return nil
+}
+
+// snippet returns the first n bytes of buf, marking truncation.
+func snippet(buf []byte, n int) string {
+	if len(buf) <= n {
+		return string(buf)
+	}
+
+	return string(buf[:n]) + "..."
+}
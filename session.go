@@ -0,0 +1,175 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Session is a client derived from a Client, eg: a logged-in user of a service.
+// It shares the transport, codecs, query encoder and middlewares of the client,
+// and keeps its own headers, cookies, cookie jar, auth and base url, so sessions never leak into each other.
+type Session struct {
+	*Client
+}
+
+// SessionState is the exported state of a session, which can be serialized as json.
+type SessionState struct {
+	BaseUrl string      `json:"baseUrl,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+	// Cookies are the common cookies set by SetCookie or AddCookies.
+	Cookies []*http.Cookie `json:"cookies,omitempty"`
+	// JarCookies are the cookies of responses kept by the cookie jar.
+	JarCookies []CookieEntry `json:"jarCookies,omitempty"`
+}
+
+// NewSession Create a session derived from the client.
+// The session starts with a copy of the headers, cookies and settings of the client and an empty in-memory cookie jar.
+// An error pointer registered by SetError on the client is used as a type, so the sessions never fill it.
+// The middlewares of the client run before the ones added by Session.Use.
+func (c *Client) NewSession() *Session {
+	jar, _ := NewCookieJar()
+
+	s := &Session{
+		Client: &Client{
+			Client: http.Client{
+				Transport:     c.Transport,
+				CheckRedirect: c.CheckRedirect,
+				Jar:           jar,
+				Timeout:       c.Timeout,
+			},
+			parent:         c,
+			ctx:            c.ctx,
+			baseUrl:        c.baseUrl,
+			base:           c.base,
+			retryCount:     c.retryCount,
+			retryInterval:  c.retryInterval,
+			statusError:    c.statusError,
+			forbidExternal: c.forbidExternal,
+			errorType:      c.errorType,
+			headers:        c.cloneHeaders(),
+			cookies:        newCookieStore(),
+			codecs:         c.codecs,
+			middlewares:    make([]MiddlewareFunc, 0),
+			queryEncoder:   c.queryEncoder,
+		},
+	}
+
+	// the order of the header lines is copied, as a session changing it must not change the client
+	if c.headerOrder != nil {
+		s.headerOrder, s.Transport = c.headerOrder.clone()
+	}
+
+	// the error pointer of the client is filled on each failure, a session decodes into a new value of its type instead
+	if c.errorValue != nil {
+		s.errorType = reflect.TypeOf(c.errorValue).Elem()
+	}
+
+	s.cookies.set(c.cookies.all()...)
+
+	return s
+}
+
+// Login Send the credentials to the url, the cookies of the response are kept by the session.
+// A non-2xx response is returned as a *StatusError.
+func (s *Session) Login(url string, data interface{}, opts ...*RequestOptions) (*Response, error) {
+	var opt RequestOptions
+	if len(opts) > 0 && opts[0] != nil {
+		opt = *opts[0]
+	}
+
+	opt.StatusError = true
+
+	return s.Post(url, data, &opt)
+}
+
+// LoginBearer Send the credentials to the url and use the token in the json response as the bearer token of the session.
+// The field is the member holding the token, nested members are separated by dots, eg: data.access_token.
+func (s *Session) LoginBearer(url string, data interface{}, field string, opts ...*RequestOptions) (*Response, error) {
+	resp, err := s.Login(url, data, opts...)
+	if err != nil {
+		return resp, err
+	}
+
+	var body map[string]interface{}
+	if err = resp.ScanBody(&body, ContentTypeJson); err != nil {
+		return resp, err
+	}
+
+	var value interface{} = body
+	for _, key := range strings.Split(field, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = m[key]
+	}
+
+	token, ok := value.(string)
+	if !ok || token == "" {
+		return resp, fmt.Errorf("http: login response has no token in %q", field)
+	}
+
+	s.SetBearerToken(token)
+
+	return resp, nil
+}
+
+// Logout Clear the auth, cookies and cookie jar of the session.
+func (s *Session) Logout() {
+	s.rw.Lock()
+	s.headers.Del(HeaderAuthorization)
+	s.rw.Unlock()
+
+	s.ClearCookies()
+
+	if jar, ok := s.Jar.(*CookieJar); ok {
+		jar.Clear()
+	}
+}
+
+// Export Returns the base url, headers and cookies of the session, the cookies of the jar are exported if it's a *CookieJar.
+func (s *Session) Export() *SessionState {
+	state := &SessionState{
+		BaseUrl: s.GetBaseUrl(),
+		Headers: s.cloneHeaders(),
+		Cookies: s.cookies.all(),
+	}
+
+	if jar, ok := s.Jar.(*CookieJar); ok {
+		state.JarCookies = jar.Entries()
+	}
+
+	return state
+}
+
+// Import Restore the state exported by a session, replacing the headers and cookies of the session.
+func (s *Session) Import(state *SessionState) error {
+	if state == nil {
+		return errors.New("http: nil session state")
+	}
+
+	if err := s.SetBaseUrl(state.BaseUrl); err != nil {
+		return err
+	}
+
+	s.rw.Lock()
+	s.headers = make(http.Header, len(state.Headers))
+	for key, values := range state.Headers {
+		s.headers[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	s.rw.Unlock()
+
+	s.ClearCookies()
+	s.AddCookies(state.Cookies...)
+
+	if jar, ok := s.Jar.(*CookieJar); ok {
+		jar.Clear()
+		jar.Add(state.JarCookies...)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dobyte/http/internal/xconv"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const templateKey = "__httpClientUrlTemplateKey"
+
+// UrlTemplate Returns the url template of the request, eg: /orgs/{org}/repos/{repo}.
+// It's useful for middlewares to group requests by route rather than by concrete url.
+func UrlTemplate(req *http.Request) string {
+	if v, ok := req.Context().Value(templateKey).(string); ok {
+		return v
+	}
+
+	return ""
+}
+
+// expandTemplate replace the placeholders in the path of the template with the escaped params.
+// A {name} placeholder is escaped as one path segment, a {name...} placeholder may contain slashes.
+// Only the braces around a name made of letters, digits and underscores are a placeholder, which must have a param,
+// the other braces, eg: an unclosed one or {"a":1}, are kept in the path as they are.
+// The . and .. segments are rejected, they would be resolved against the base url and escape its path.
+func expandTemplate(template string, params map[string]string) (string, error) {
+	path, query := template, ""
+	if i := strings.IndexByte(template, '?'); i >= 0 {
+		path, query = template[:i], template[i:]
+	}
+
+	if !strings.Contains(path, "{") {
+		return template, nil
+	}
+
+	var sb strings.Builder
+
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			sb.WriteString(path)
+			break
+		}
+
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			sb.WriteString(path)
+			break
+		}
+		end += start
+
+		name, wildcard := path[start+1:end], false
+		if strings.HasSuffix(name, "...") {
+			name, wildcard = strings.TrimSuffix(name, "..."), true
+		}
+
+		if !isPlaceholderName(name) {
+			sb.WriteString(path[:end+1])
+			path = path[end+1:]
+			continue
+		}
+
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("http: missing path param %q for url template %q", name, template)
+		}
+
+		sb.WriteString(path[:start])
+
+		segments := []string{value}
+		if wildcard {
+			segments = strings.Split(strings.TrimLeft(value, "/"), "/")
+		}
+
+		for i, segment := range segments {
+			if segment == "." || segment == ".." {
+				return "", fmt.Errorf("http: invalid path param %q for url template %q: dot segment", name, template)
+			}
+			segments[i] = url.PathEscape(segment)
+		}
+
+		sb.WriteString(strings.Join(segments, "/"))
+
+		path = path[end+1:]
+	}
+
+	return sb.String() + query, nil
+}
+
+// isPlaceholderName determine if the name of a placeholder is made of letters, digits and underscores.
+func isPlaceholderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		if c != '_' && (c < '0' || c > '9') && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parsePathParams convert a map or a struct with path tags into path params.
+func parsePathParams(params interface{}) (map[string]string, error) {
+	switch v := params.(type) {
+	case nil:
+		return nil, nil
+	case map[string]string:
+		return v, nil
+	case map[string]interface{}:
+		m := make(map[string]string, len(v))
+		for key, value := range v {
+			m[key] = xconv.String(value)
+		}
+		return m, nil
+	}
+
+	rv := reflect.ValueOf(params)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		m := make(map[string]string, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[xconv.String(iter.Key().Interface())] = xconv.String(iter.Value().Interface())
+		}
+		return m, nil
+	case reflect.Struct:
+		var (
+			rt = rv.Type()
+			m  = make(map[string]string, rv.NumField())
+		)
+
+		for i := 0; i < rv.NumField(); i++ {
+			field := rt.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name := strings.Split(field.Tag.Get("path"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			m[name] = xconv.String(rv.Field(i).Interface())
+		}
+		return m, nil
+	default:
+		return nil, errors.New("http: path params must be map or struct")
+	}
+}
@@ -0,0 +1,106 @@
+package test_test
+
+import (
+	"github.com/dobyte/http"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClient_AddCookies(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Write([]byte(r.Header.Get(http.HeaderCookie)))
+	}))
+	defer server.Close()
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetCookie("lang", "en")
+	client.AddCookies(
+		&stdhttp.Cookie{Name: "sid", Value: "root", Domain: "127.0.0.1"},
+		&stdhttp.Cookie{Name: "sid", Value: "api", Domain: "127.0.0.1", Path: "/api"},
+		&stdhttp.Cookie{Name: "other", Value: "1", Domain: "example.com"},
+		&stdhttp.Cookie{Name: "secure", Value: "1", Secure: true},
+		&stdhttp.Cookie{Name: "expired", Value: "1", Expires: time.Now().Add(-time.Hour)},
+	)
+
+	tests := []struct {
+		url  string
+		opts *http.RequestOptions
+		want string
+	}{
+		{url: "/", want: "lang=en; sid=root"},
+		{url: "/api/users", want: "sid=api; lang=en; sid=root"},
+		{url: "/apis", want: "lang=en; sid=root"},
+		{url: "/", opts: &http.RequestOptions{Cookies: map[string]string{"lang": "fr", "a": "b"}}, want: "sid=root; a=b; lang=fr"},
+	}
+
+	for _, tt := range tests {
+		resp, err := client.Get(tt.url, nil, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if body, _ := resp.ReadBody(); string(body) != tt.want {
+			t.Errorf("Get(%s) Cookie = %s, want %s", tt.url, body, tt.want)
+		}
+	}
+
+	client.DelCookie("sid")
+	if cookies := client.GetCookiesFor(server.URL + "/api"); len(cookies) != 1 || cookies[0].Name != "lang" {
+		t.Errorf("GetCookiesFor() = %v", cookies)
+	}
+
+	if cookies := client.GetCookies(); len(cookies) != 3 {
+		t.Errorf("GetCookies() = %v", cookies)
+	}
+}
+
+func TestClient_SetCookieJar(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		if r.URL.Path == "/login" {
+			stdhttp.SetCookie(w, &stdhttp.Cookie{Name: "sid", Value: "abc", MaxAge: 3600})
+			return
+		}
+		w.Write([]byte(r.Header.Get(http.HeaderCookie)))
+	}))
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "cookies.txt")
+
+	jar, err := http.NewCookieJar(&http.CookieJarOptions{Filename: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetCookieJar(jar)
+
+	if _, err = client.Get("/login", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = jar.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if jar, err = http.NewCookieJar(&http.CookieJarOptions{Filename: filename}); err != nil {
+		t.Fatal(err)
+	}
+
+	client = http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetCookieJar(jar)
+
+	resp, err := client.Get("/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := resp.ReadBody(); string(body) != "sid=abc" {
+		t.Errorf("Get() Cookie = %s, want sid=abc", body)
+	}
+}
@@ -0,0 +1,209 @@
+package test_test
+
+import (
+	"encoding/json"
+	"github.com/dobyte/http"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type echo struct {
+	Method string
+	Url    string
+	Header stdhttp.Header
+	Body   string
+	Form   map[string][]string
+	Files  map[string]string
+}
+
+func newEchoServer() *httptest.Server {
+	return httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		e := echo{Method: r.Method, Url: r.URL.String(), Header: r.Header}
+
+		if strings.HasPrefix(r.Header.Get(http.HeaderContentType), "multipart/form-data") {
+			r.ParseMultipartForm(1 << 20)
+			e.Form, e.Files = r.MultipartForm.Value, make(map[string]string)
+			for name, files := range r.MultipartForm.File {
+				f, _ := files[0].Open()
+				buf, _ := io.ReadAll(f)
+				e.Files[name] = files[0].Filename + ":" + files[0].Header.Get(http.HeaderContentType) + ":" + string(buf)
+			}
+		} else {
+			buf, _ := io.ReadAll(r.Body)
+			e.Body = string(buf)
+		}
+
+		json.NewEncoder(w).Encode(e)
+	}))
+}
+
+func TestClient_Curl(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	client := http.NewClient()
+	client.SetHeader("X-Common", "1")
+
+	command := `curl '` + server.URL + `/api/users?page=1&sort[]=name' \
+  -H 'accept: application/json' \
+  -H 'cookie: sid=abc; theme=dark' \
+  -H "x-quote: \"a\" \$b" \
+  --data-raw $'{"name":"o\'neil","bio":"a\\nb"}' \
+  --compressed`
+
+	var e echo
+
+	resp, err := client.Curl(command, &http.CurlOptions{RequestOptions: http.RequestOptions{Cookies: map[string]string{"theme": "light"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = resp.ScanBody(&e); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Method != http.MethodPost || e.Url != "/api/users?page=1&sort[]=name" {
+		t.Errorf("Curl() sent %s %s", e.Method, e.Url)
+	}
+
+	if want := `{"name":"o'neil","bio":"a\nb"}`; e.Body != want {
+		t.Errorf("Curl() body = %s, want %s", e.Body, want)
+	}
+
+	for key, want := range map[string]string{
+		"Accept":          "application/json",
+		"Content-Type":    http.ContentTypeFormUrlEncoded,
+		"X-Quote":         `"a" $b`,
+		"X-Common":        "1",
+		"Accept-Encoding": "gzip",
+	} {
+		if got := e.Header.Get(key); got != want {
+			t.Errorf("Curl() header %s = %q, want %q", key, got, want)
+		}
+	}
+
+	if cookie := e.Header.Get(http.HeaderCookie); !strings.Contains(cookie, "sid=abc") || !strings.Contains(cookie, "theme=light") {
+		t.Errorf("Curl() cookie = %s", cookie)
+	}
+
+	resp, err = client.Curl(`curl -sSGL -XPUT -u admin:secret --data-urlencode 'q=a b&c' -d x=1 ` + server.URL + `/search`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = resp.ScanBody(&e); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Method != http.MethodPut || e.Url != "/search?q=a+b%26c&x=1" || e.Body != "" {
+		t.Errorf("Curl() sent %s %s %q", e.Method, e.Url, e.Body)
+	}
+
+	if auth := e.Header.Get(http.HeaderAuthorization); auth != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("Curl() authorization = %s", auth)
+	}
+
+	if _, err = client.Curl(`curl --proxy http://localhost:8080 ` + server.URL); err == nil {
+		t.Errorf("Curl() with an unsupported option succeeded")
+	}
+
+	if _, err = client.Curl(`curl -sk ` + server.URL); err != nil {
+		t.Errorf("Curl() with -k on a client skipping the verification err = %v", err)
+	}
+
+	resp, err = client.Curl(`curl -G ` + server.URL + `/s -d q=1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e = echo{}
+	if err = resp.ScanBody(&e); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Method != http.MethodGet || e.Url != "/s?q=1" || e.Body != "" || e.Header.Get(http.HeaderContentType) != "" {
+		t.Errorf("Curl() with -G sent %s %s %q with Content-Type %q", e.Method, e.Url, e.Body, e.Header.Get(http.HeaderContentType))
+	}
+
+	verifying := http.NewClient()
+	verifying.Transport = &stdhttp.Transport{}
+
+	if _, err = verifying.Curl(`curl -k ` + server.URL); err == nil {
+		t.Errorf("Curl() with -k on a client verifying certificates succeeded")
+	}
+}
+
+func TestClient_ParseCurl(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	filename := filepath.Join(dir, "avatar.txt")
+	os.WriteFile(filename, []byte("hello"), 0644)
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL + "/api")
+
+	command := `curl -F 'file=@` + filename + `;filename=me.txt' -F 'meta={"a":1};type=application/json' --form-string 'note=@not-a-file' /upload`
+
+	if _, err := client.ParseCurl(command); err == nil {
+		t.Errorf("ParseCurl() read a file without FileDir")
+	}
+
+	opts := &http.CurlOptions{FileDir: dir}
+
+	for _, outside := range []string{"-d @../secret", "--data-binary @/etc/hostname", "--data-urlencode a@/etc/hostname", "-F f=@/etc/hostname"} {
+		if _, err := client.ParseCurl(`curl `+outside+` /upload`, opts); err == nil {
+			t.Errorf("ParseCurl(%s) read a file out of FileDir", outside)
+		}
+	}
+
+	req, err := client.ParseCurl(command, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req, err := client.ParseCurl(`curl -d @avatar.txt /upload`, opts); err != nil || req.ContentLength != 5 {
+		t.Errorf("ParseCurl() relative file in FileDir = %v, %v", req, err)
+	}
+
+	if req.URL.String() != server.URL+"/api/upload" {
+		t.Errorf("ParseCurl() url = %s", req.URL)
+	}
+
+	command, err = http.ToCurl(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `-F 'file=@` + filename + `;filename=me.txt'`; !strings.Contains(command, want) {
+		t.Errorf("ToCurl() = %s, want %s", command, want)
+	}
+
+	resp, err := client.Curl(command, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var e echo
+	if err = resp.ScanBody(&e); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := e.Files["file"]; got != "me.txt:text/plain; charset=utf-8:hello" {
+		t.Errorf("Curl() file = %s", got)
+	}
+
+	if got := e.Form["meta"]; len(got) != 1 || got[0] != `{"a":1}` {
+		t.Errorf("Curl() meta = %v", got)
+	}
+
+	if got := e.Form["note"]; len(got) != 1 || got[0] != "@not-a-file" {
+		t.Errorf("Curl() note = %v", got)
+	}
+}
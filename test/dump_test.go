@@ -0,0 +1,121 @@
+package test_test
+
+import (
+	"bytes"
+	"github.com/dobyte/http"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClient_SetDebug(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Header().Set(http.HeaderContentType, "text/plain")
+		w.Write([]byte(strings.Repeat("a", 20)))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetCookie("sid", "abc")
+	client.SetBearerToken("secret")
+	client.SetDebug(true, &buf)
+	client.Use(func(r http.Request) (*http.Response, error) {
+		r.Request().Header.Set("X-Middleware", "1")
+		return r.Next()
+	})
+
+	resp, err := client.Post("/users", map[string]string{"name": "fuxiao"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"POST /users HTTP/1.1\r\n",
+		"Cookie: [REDACTED]\r\n",
+		"Authorization: [REDACTED]\r\n",
+		"X-Middleware: 1\r\n",
+		"Content-Length: 11\r\n",
+		"\r\n\r\nname=fuxiao",
+		"HTTP/1.1 200 OK\r\n",
+		strings.Repeat("a", 20),
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("debug output has no %q:\n%s", want, buf.String())
+		}
+	}
+
+	if strings.Contains(buf.String(), "secret") || strings.Contains(buf.String(), "sid=abc") {
+		t.Errorf("debug output isn't redacted:\n%s", buf.String())
+	}
+
+	if dump := resp.Dump(8); !strings.Contains(dump, "aaaaaaaa\n...(12 bytes truncated)") || !strings.Contains(dump, "Cookie: sid=abc\r\n") {
+		t.Errorf("Dump() = %s", dump)
+	}
+
+	if body, _ := resp.ReadBody(); len(body) != 20 {
+		t.Errorf("ReadBody() after Dump() = %s", body)
+	}
+
+	client.SetDebug(false)
+
+	if resp, err = client.Get("/", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if dump := resp.Dump(8); !strings.Contains(dump, "aaaaaaaa\n...(12 bytes truncated)") {
+		t.Errorf("Dump() of an unread body = %s", dump)
+	}
+
+	if body, _ := resp.ReadBody(); string(body) != strings.Repeat("a", 20) {
+		t.Errorf("ReadBody() after Dump() of an unread body = %s", body)
+	}
+}
+
+func TestToCurl(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {}))
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "it's.txt")
+	os.WriteFile(filename, []byte("hello"), 0644)
+
+	var commands []string
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetUserAgent("test")
+	client.Use(func(r http.Request) (*http.Response, error) {
+		command, err := r.ToCurl()
+		if err != nil {
+			t.Fatal(err)
+		}
+		commands = append(commands, command)
+		return r.Next()
+	})
+
+	client.Post("/users?a=1&b=2", map[string]string{"name": "o'neil"}, &http.RequestOptions{
+		Headers: map[string]string{http.HeaderContentType: http.ContentTypeJson},
+		Cookies: map[string]string{"sid": "abc"},
+	})
+	client.Upload("/files", map[string]string{"file": filename}, map[string]string{"title": "a;b"})
+
+	want := []string{
+		`curl -X POST '` + server.URL + `/users?a=1&b=2' -H 'Content-Type: application/json' -b sid=abc -H 'User-Agent: test' --data-binary '{"name":"o'\''neil"}'`,
+		`curl -X POST ` + server.URL + `/files -H 'User-Agent: test' -F 'file=@` + strings.ReplaceAll(filename, "'", `'\''`) + `' --form-string 'title=a;b'`,
+	}
+
+	if len(commands) != len(want) {
+		t.Fatalf("ToCurl() called %d times, want %d", len(commands), len(want))
+	}
+
+	for i, command := range commands {
+		if command != want[i] {
+			t.Errorf("ToCurl() got:\n%s\nwant:\n%s", command, want[i])
+		}
+	}
+}
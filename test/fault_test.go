@@ -0,0 +1,138 @@
+package test_test
+
+import (
+	"context"
+	"errors"
+	"github.com/dobyte/http"
+	"io"
+	"net"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewFaultMiddleware(t *testing.T) {
+	var hits int32
+
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetRetry(2, 0)
+
+	fault, err := http.NewFaultMiddleware(&http.FaultOptions{Rules: []http.FaultRule{
+		{Host: "other.example.com", StatusProbability: 1, Status: 500},
+		{Method: http.MethodGet, Path: "/users/{id}", TimeoutProbability: 1},
+		{Method: http.MethodPost, Path: "/orders", Status: 503, StatusProbability: 1},
+		{Path: "/files/drop", DropAfter: 5, DropProbability: 1},
+		{Path: "/files/*", TruncateAt: 4, TruncateProbability: 1, Latency: 30 * time.Millisecond, LatencyProbability: 1},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Use(fault)
+
+	_, err = client.Get("/users/{id}", nil, &http.RequestOptions{PathParams: map[string]string{"id": "1"}})
+
+	var ne net.Error
+	if !errors.As(err, &ne) || !ne.Timeout() || !errors.Is(err, context.DeadlineExceeded) || hits != 0 {
+		t.Errorf("Get() = %v after %d hits, want a timeout", err, hits)
+	}
+
+	resp, err := client.Post("/orders", nil)
+	if err != nil || resp.StatusCode != 503 || resp.GetHeader(http.HeaderFaultInjected) != "status" || hits != 0 {
+		t.Errorf("Post() = %v, %v after %d hits", resp, err, hits)
+	}
+
+	if resp, err = client.Get("/orders", nil); err != nil || resp.StatusCode != 200 || hits != 1 {
+		t.Errorf("Get() of an unmatched request = %v, %v", resp, err)
+	}
+
+	if resp, err = client.Get("/files/drop", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if body, err := resp.ReadBody(); !errors.Is(err, io.ErrUnexpectedEOF) || string(body) != "01234" {
+		t.Errorf("ReadBody() of a dropped body = %s, %v", body, err)
+	}
+
+	start := time.Now()
+
+	if resp, err = client.Get("/files/a.txt", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if body, err := resp.ReadBody(); err != nil || string(body) != "0123" || resp.ContentLength != 4 {
+		t.Errorf("ReadBody() of a truncated body = %s, %v", body, err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Get() with latency took %v", elapsed)
+	}
+}
+
+func TestNewFaultMiddleware_Seed(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {}))
+	defer server.Close()
+
+	run := func(seed int64) string {
+		client := http.NewClient()
+		client.SetBaseUrl(server.URL)
+		fault, err := http.NewFaultMiddleware(&http.FaultOptions{
+			Seed:  seed,
+			Rules: []http.FaultRule{{Status: 503, StatusProbability: 0.5}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client.Use(fault)
+
+		var sb strings.Builder
+		for i := 0; i < 32; i++ {
+			if resp, err := client.Get("/", nil); err != nil || resp.StatusCode == 503 {
+				sb.WriteByte('x')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+
+		return sb.String()
+	}
+
+	first := run(42)
+	if second := run(42); first != second {
+		t.Errorf("seeded runs differ: %s and %s", first, second)
+	}
+
+	if !strings.Contains(first, "x") || !strings.Contains(first, ".") {
+		t.Errorf("seeded run with probability 0.5 = %s", first)
+	}
+}
+
+func TestNewFaultMiddleware_InvalidRule(t *testing.T) {
+	for _, rule := range []http.FaultRule{
+		{StatusProbability: 1},
+		{Status: 42, StatusProbability: 1},
+		{LatencyProbability: 0.5},
+		{Status: 503, StatusProbability: 1.5},
+		{Latency: time.Second, LatencyProbability: -1},
+		{Path: "/users/[", DropProbability: 1},
+	} {
+		if _, err := http.NewFaultMiddleware(&http.FaultOptions{Rules: []http.FaultRule{rule}}); err == nil {
+			t.Errorf("NewFaultMiddleware(%+v) succeeded", rule)
+		}
+	}
+
+	if _, err := http.NewFaultMiddleware(&http.FaultOptions{Rules: []http.FaultRule{{Status: 503}}}); err != nil {
+		t.Errorf("NewFaultMiddleware() of a rule without probabilities = %v", err)
+	}
+}
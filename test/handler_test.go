@@ -0,0 +1,150 @@
+package test_test
+
+import (
+	"bufio"
+	"github.com/dobyte/http"
+	"io"
+	stdhttp "net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_SetHandler(t *testing.T) {
+	var (
+		mux      = stdhttp.NewServeMux()
+		canceled = make(chan struct{})
+		release  = make(chan struct{})
+	)
+
+	mux.HandleFunc("/login", func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		stdhttp.SetCookie(w, &stdhttp.Cookie{Name: "sid", Value: "abc", Path: "/"})
+		w.Write([]byte(r.Host + " " + r.RemoteAddr + " " + r.URL.String()))
+	})
+	mux.HandleFunc("/me", func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		cookie, _ := r.Cookie("sid")
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Write([]byte(cookie.String() + " " + string(body)))
+		w.Header().Set("X-Checksum", "42")
+	})
+	mux.HandleFunc("/stream", func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Write([]byte("first\n"))
+		w.(stdhttp.Flusher).Flush()
+		<-release
+		w.Write([]byte("second\n"))
+	})
+	mux.HandleFunc("/slow", func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		<-r.Context().Done()
+		close(canceled)
+	})
+	mux.HandleFunc("/panic", func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		panic("boom")
+	})
+
+	var middlewares int
+
+	client := http.NewClient()
+	client.SetHandler(mux)
+	client.SetBrowserMode()
+	client.SetBaseUrl("https://service.local/")
+	client.Use(func(r http.Request) (*http.Response, error) {
+		middlewares++
+		return r.Next()
+	})
+
+	resp, err := client.Get("/login?a=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := resp.ReadBody(); string(body) != "service.local 192.0.2.1:1234 /login?a=1" {
+		t.Errorf("Get() = %s", body)
+	}
+
+	if resp, err = client.Post("/me", "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := resp.ReadBody(); string(body) != "sid=abc hello" || resp.Trailer.Get("X-Checksum") != "42" {
+		t.Errorf("Post() = %s, trailer %v", body, resp.Trailer)
+	}
+
+	if resp, err = client.Get("/stream", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != "first\n" {
+		t.Errorf("stream first line = %q", line)
+	}
+
+	close(release)
+
+	if line, _ := reader.ReadString('\n'); line != "second\n" {
+		t.Errorf("stream second line = %q", line)
+	}
+	resp.Close()
+
+	client.SetTimeout(20 * time.Millisecond)
+
+	if _, err = client.Get("/slow", nil); err == nil {
+		t.Error("Get() of a slow handler succeeded")
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("the context of the slow handler wasn't canceled")
+	}
+
+	if _, err = client.Get("/panic", nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Get() of a panicking handler = %v", err)
+	}
+
+	if middlewares != 5 {
+		t.Errorf("middlewares called %d times, want 5", middlewares)
+	}
+}
+
+func TestClient_SetHandler_Upgrade(t *testing.T) {
+	client := http.NewClient()
+	client.SetHandler(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		conn, rw, err := w.(stdhttp.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+
+		line, _ := rw.ReadString('\n')
+		rw.WriteString(strings.ToUpper(line))
+		rw.Flush()
+	}))
+
+	resp, err := client.Get("http://service.local/ws", nil, &http.RequestOptions{
+		Headers: map[string]string{"Connection": "Upgrade", "Upgrade": "echo"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != stdhttp.StatusSwitchingProtocols {
+		t.Fatalf("Get() status = %d", resp.StatusCode)
+	}
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		t.Fatalf("body %T isn't an io.ReadWriteCloser", resp.Body)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("hello\n"))
+
+	if line, _ := bufio.NewReader(conn).ReadString('\n'); line != "HELLO\n" {
+		t.Errorf("upgraded connection read %q", line)
+	}
+}
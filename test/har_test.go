@@ -0,0 +1,115 @@
+package test_test
+
+import (
+	"encoding/json"
+	"github.com/dobyte/http"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewHarRecorder(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		stdhttp.SetCookie(w, &stdhttp.Cookie{Name: "sid", Value: "abc", Path: "/", HttpOnly: true})
+		w.Header().Set(http.HeaderContentType, "text/plain")
+		w.WriteHeader(stdhttp.StatusCreated)
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+
+	recorder := http.NewHarRecorder(&http.HarRecorderOptions{MaxResponseBodySize: 10})
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetCookie("theme", "dark")
+	client.Use(recorder.Middleware())
+
+	resp, err := client.Post("/users?page=1", map[string]string{"name": "fuxiao"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := resp.ReadBody(); len(body) != 100 {
+		t.Errorf("ReadBody() = %d bytes, want 100", len(body))
+	}
+
+	server.Close()
+
+	if _, err = client.Get("/closed", nil); err == nil {
+		t.Fatal("Get() of a closed server succeeded")
+	}
+
+	filename := filepath.Join(t.TempDir(), "session.har")
+	if err = recorder.SaveFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var h http.Har
+	if err = json.Unmarshal(buf, &h); err != nil {
+		t.Fatal(err)
+	}
+
+	if h.Log.Version != "1.2" || len(h.Log.Entries) != 2 {
+		t.Fatalf("Har() = version %s with %d entries", h.Log.Version, len(h.Log.Entries))
+	}
+
+	entry := h.Log.Entries[0]
+
+	if req := entry.Request; req.Method != http.MethodPost || req.URL != server.URL+"/users?page=1" ||
+		len(req.QueryString) != 1 || len(req.Cookies) != 1 || req.Cookies[0].Value != "[REDACTED]" ||
+		req.PostData == nil || req.PostData.Text != "name=fuxiao" || req.PostData.Params[0].Value != "fuxiao" || req.BodySize != 11 {
+		t.Errorf("Har() request = %+v", req)
+	}
+
+	if resp := entry.Response; resp.Status != 201 || resp.StatusText != "Created" ||
+		len(resp.Cookies) != 1 || !resp.Cookies[0].HTTPOnly ||
+		resp.Content.Size != 100 || resp.Content.Text != "aaaaaaaaaa" || resp.Content.Comment != "truncated, 10 of 100 bytes recorded" {
+		t.Errorf("Har() response = %+v", resp)
+	}
+
+	if entry.ServerIPAddress != "127.0.0.1" || entry.Timings.Connect < 0 || entry.Time <= 0 {
+		t.Errorf("Har() entry = %s %+v %v", entry.ServerIPAddress, entry.Timings, entry.Time)
+	}
+
+	if failed := h.Log.Entries[1]; failed.Response.Status != 0 || failed.Error == "" {
+		t.Errorf("Har() failed entry = %+v", failed)
+	}
+
+	if strings.Contains(string(buf), "dark") || strings.Contains(string(buf), "sid=abc") || strings.Contains(string(buf), `"abc"`) {
+		t.Errorf("Har() has unredacted cookies:\n%s", buf)
+	}
+}
+
+func TestHarRecorder_DisableRedaction(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {}))
+	defer server.Close()
+
+	recorder := http.NewHarRecorder(&http.HarRecorderOptions{DisableRedaction: true})
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetBearerToken("secret")
+	client.Use(recorder.Middleware())
+
+	if _, err := client.Get("/", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var authorization string
+	for _, header := range recorder.Har().Log.Entries[0].Request.Headers {
+		if header.Name == http.HeaderAuthorization {
+			authorization = header.Value
+		}
+	}
+
+	if authorization != "Bearer secret" {
+		t.Errorf("Har() authorization = %q, want it unredacted", authorization)
+	}
+}
@@ -0,0 +1,159 @@
+package test_test
+
+import (
+	"bufio"
+	"github.com/dobyte/http"
+	"net"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_AddHeader(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Write([]byte(strings.Join(r.Header.Values("Accept"), ",") + "|" + strings.Join(r.Header.Values("X-Forwarded-For"), ",") + "|" + r.Header.Get("X-Tenant")))
+	}))
+	defer server.Close()
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetHeader("accept", "text/html")
+	client.AddHeader("Accept", "application/json")
+	client.AddHeader("X-Forwarded-For", "10.0.0.1")
+	client.SetHeader("X-Tenant", "a")
+	client.DelHeader("x-tenant")
+
+	if got := client.GetHeaderValues("ACCEPT"); len(got) != 2 {
+		t.Errorf("GetHeaderValues() = %v", got)
+	}
+
+	resp, err := client.Get("/", nil, &http.RequestOptions{
+		Headers: map[string]string{"x-tenant": "b"},
+		Header:  stdhttp.Header{"X-Forwarded-For": {"10.0.0.2", "10.0.0.3"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := resp.ReadBody()
+	if want := "text/html,application/json|10.0.0.2,10.0.0.3|b"; string(body) != want {
+		t.Errorf("Get() = %s, want %s", body, want)
+	}
+}
+
+// newHeaderKeysServer starts a server sending the keys of the header lines of each request, in the order on the wire.
+func newHeaderKeysServer(t *testing.T) (string, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	lines := make(chan []string, 1)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			var (
+				reader = bufio.NewReader(conn)
+				keys   []string
+			)
+
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil || line == "\r\n" {
+					break
+				}
+
+				if key, _, ok := strings.Cut(line, ":"); ok && !strings.Contains(key, " ") {
+					keys = append(keys, key)
+				}
+			}
+
+			lines <- keys
+			conn.Write([]byte("HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	return "http://" + listener.Addr().String() + "/", lines
+}
+
+func TestClient_SetHeaderOrder(t *testing.T) {
+	url, lines := newHeaderKeysServer(t)
+
+	client := http.NewClient()
+	client.SetHeader("X-Zeta", "1")
+	client.SetHeader("Accept", "*/*")
+
+	if err := client.SetHeaderOrder("User-Agent", "x-zeta", "Host", "Accept"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Get(url, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got := <-lines
+	if want := "User-Agent,X-Zeta,Host,Accept"; !strings.HasPrefix(strings.Join(got, ","), want) {
+		t.Errorf("header order = %v, want prefix %s", got, want)
+	}
+
+	server := httptest.NewTLSServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	defer server.Close()
+
+	resp, err := client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := resp.ReadBody(); string(body) != "HTTP/1.1" {
+		t.Errorf("Get() over tls = %s", body)
+	}
+}
+
+func TestSession_SetHeaderOrder(t *testing.T) {
+	url, lines := newHeaderKeysServer(t)
+
+	client := http.NewClient()
+	client.SetHeader("Accept", "*/*")
+
+	if err := client.SetHeaderOrder("User-Agent", "Accept"); err != nil {
+		t.Fatal(err)
+	}
+
+	alice, bob := client.NewSession(), client.NewSession()
+
+	if err := alice.SetHeaderOrder("Accept", "User-Agent"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		client interface {
+			Get(url string, data interface{}, opts ...*http.RequestOptions) (*http.Response, error)
+		}
+		want string
+	}{
+		{name: "client", client: client, want: "User-Agent,Accept"},
+		{name: "alice", client: alice, want: "Accept,User-Agent"},
+		{name: "bob", client: bob, want: "User-Agent,Accept"},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.client.Get(url, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := <-lines; !strings.HasPrefix(strings.Join(got, ","), tt.want) {
+			t.Errorf("%s: header order = %v, want prefix %s", tt.name, got, tt.want)
+		}
+	}
+}
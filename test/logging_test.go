@@ -0,0 +1,118 @@
+package test_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/dobyte/http"
+	"io"
+	"log/slog"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewLoggingMiddleware(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Header().Set(http.HeaderContentType, http.ContentTypeJson)
+		w.Header().Set("Set-Cookie", "sid=secret")
+		w.WriteHeader(stdhttp.StatusNotFound)
+		w.Write([]byte(`{"token":"abc","message":"` + strings.Repeat("x", 100) + `"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetBearerToken("secret")
+	client.Use(http.NewLoggingMiddleware(&http.LoggingOptions{
+		Logger:          slog.New(slog.NewJSONHandler(&buf, nil)),
+		LogHeaders:      true,
+		LogRequestBody:  true,
+		LogResponseBody: true,
+		MaxBodySize:     64,
+		RedactFields:    []string{"Password", "token"},
+	}))
+
+	_, err := client.Post("/users/{id}", map[string]interface{}{"name": "fuxiao", "password": "123456"}, &http.RequestOptions{
+		Headers:    map[string]string{http.HeaderContentType: http.ContentTypeJson},
+		PathParams: map[string]string{"id": "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var record map[string]interface{}
+	if err = json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err, buf.String())
+	}
+
+	want := map[string]interface{}{
+		"level":         "WARN",
+		"method":        "POST",
+		"template":      "/users/{id}",
+		"status":        float64(404),
+		"attempts":      float64(1),
+		"request_body":  `{"name":"fuxiao","password":"[REDACTED]"}`,
+		"response_body": `{"message":"` + strings.Repeat("x", 52) + `...(71 bytes truncated)`,
+	}
+
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("record[%s] = %v, want %v", key, record[key], value)
+		}
+	}
+
+	if headers := record["request_headers"].(map[string]interface{}); headers["Authorization"] != "[REDACTED]" {
+		t.Errorf("request Authorization = %v", headers["Authorization"])
+	}
+
+	if headers := record["response_headers"].(map[string]interface{}); headers["Set-Cookie"] != "[REDACTED]" {
+		t.Errorf("response Set-Cookie = %v", headers["Set-Cookie"])
+	}
+
+	if strings.Contains(buf.String(), "secret") || strings.Contains(buf.String(), "123456") {
+		t.Errorf("record leaks secrets: %s", buf.String())
+	}
+}
+
+func TestNewLoggingMiddleware_Attempts(t *testing.T) {
+	var calls int
+
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		if calls++; calls == 1 {
+			conn, _, _ := w.(stdhttp.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetRetry(2, 0)
+	client.Use(http.NewLoggingMiddleware(&http.LoggingOptions{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}))
+
+	resp, err := client.Post("/", `{"retry":true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := resp.ReadBody(); string(body) != `{"retry":true}` {
+		t.Errorf("Post() body = %s", body)
+	}
+
+	var record map[string]interface{}
+	if err = json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record["attempts"] != float64(2) || record["level"] != "INFO" {
+		t.Errorf("record = %v", record)
+	}
+}
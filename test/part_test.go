@@ -0,0 +1,108 @@
+package test_test
+
+import (
+	"fmt"
+	"github.com/dobyte/http"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponse_Parts(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Header().Set("Content-Type", "multipart/mixed; boundary=batch")
+		fmt.Fprint(w, "--batch\r\n"+
+			"Content-Type: application/http\r\n"+
+			"Content-ID: <response-1>\r\n\r\n"+
+			"HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 9\r\n\r\n{\"id\":1}\n\r\n"+
+			"--batch\r\n"+
+			"Content-Type: application/http\r\n"+
+			"Content-ID: <response-2>\r\n\r\n"+
+			"HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n\r\n"+
+			"--batch--\r\n")
+	}))
+	defer server.Close()
+
+	resp, err := http.NewClient().Get(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts, err := resp.Parts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responses, err := parts.Responses()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(responses) != 2 {
+		t.Fatalf("Parts() got %d responses, want 2", len(responses))
+	}
+
+	var user struct {
+		ID int `json:"id"`
+	}
+
+	if err = responses[0].ScanBody(&user); err != nil || user.ID != 1 {
+		t.Errorf("ScanBody() user = %+v, err = %v", user, err)
+	}
+
+	if responses[1].StatusCode != stdhttp.StatusNotFound {
+		t.Errorf("Parts() second status = %d, want 404", responses[1].StatusCode)
+	}
+}
+
+type buffer []byte
+
+func (b buffer) WriteAt(p []byte, off int64) (int, error) {
+	return copy(b[off:], p), nil
+}
+
+func TestPart_WriteRangeTo(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Header().Set("Content-Type", "multipart/byteranges; boundary=range")
+		w.WriteHeader(stdhttp.StatusPartialContent)
+		fmt.Fprint(w, "--range\r\n"+
+			"Content-Range: bytes 0-4/11\r\n\r\n"+
+			"hello\r\n"+
+			"--range\r\n"+
+			"Content-Range: bytes 6-10/11\r\n\r\n"+
+			"world\r\n"+
+			"--range--\r\n")
+	}))
+	defer server.Close()
+
+	resp, err := http.NewClient().Get(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts, err := resp.Parts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := buffer(strings.Repeat(" ", 11))
+	for {
+		part, err := parts.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = part.WriteRangeTo(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if string(buf) != "hello world" {
+		t.Errorf("WriteRangeTo() got %q, want %q", string(buf), "hello world")
+	}
+}
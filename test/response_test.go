@@ -0,0 +1,317 @@
+package test_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"github.com/dobyte/http"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestResponse_ScanBody(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		switch r.URL.Path {
+		case "/xml":
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.Write([]byte(`<user><id>1</id><name>fuxiao</name></user>`))
+		case "/form":
+			w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+			w.Write([]byte(`id=1&name=fuxiao&tags=a&tags=b`))
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(`<user><id>x</id></user>`))
+		}
+	}))
+	defer server.Close()
+
+	type user struct {
+		ID   int      `xml:"id" form:"id"`
+		Name string   `xml:"name" form:"name"`
+		Tags []string `form:"tags"`
+	}
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+
+	resp, err := client.Get("/xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var u1 user
+	if err = resp.ScanBody(&u1); err != nil || u1.ID != 1 || u1.Name != "fuxiao" {
+		t.Errorf("ScanBody() xml = %+v, err = %v", u1, err)
+	}
+
+	resp, err = client.Get("/form", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var u2 user
+	if err = resp.ScanBody(&u2); err != nil || u2.ID != 1 || len(u2.Tags) != 2 {
+		t.Errorf("ScanBody() form = %+v, err = %v", u2, err)
+	}
+
+	var values url.Values
+	if err = resp.ScanBody(&values); err != nil || values.Get("name") != "fuxiao" {
+		t.Errorf("ScanBody() form values = %v, err = %v", values, err)
+	}
+
+	resp, err = client.Get("/text", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s string
+	if err = resp.ScanBody(&s); err != nil || s != `<user><id>x</id></user>` {
+		t.Errorf("ScanBody() text = %q, err = %v", s, err)
+	}
+
+	var u3 user
+	var decodeErr *http.DecodeError
+	if err = resp.ScanBody(&u3, http.ContentTypeXml); !errors.As(err, &decodeErr) {
+		t.Errorf("ScanBody() override err = %v, want *DecodeError", err)
+	} else if decodeErr.ContentType != http.ContentTypeXml || decodeErr.Snippet == "" {
+		t.Errorf("ScanBody() decode error = %+v", decodeErr)
+	}
+}
+
+type csvCodec struct{}
+
+func (csvCodec) ContentType() string { return "text/csv" }
+
+func (csvCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte(strings.Join(v.([]string), ",")), nil
+}
+
+func (csvCodec) Unmarshal(data []byte, v interface{}) error {
+	*(v.(*[]string)) = strings.Split(string(data), ",")
+	return nil
+}
+
+func TestClient_RegisterCodec(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		switch r.URL.Path {
+		case "/csv":
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			io.Copy(w, r.Body)
+		default:
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.Write([]byte(`{"id":1}`))
+		}
+	}))
+	defer server.Close()
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.RegisterCodec(csvCodec{})
+
+	resp, err := client.Post("/csv", []string{"a", "b"}, &http.RequestOptions{
+		Headers: map[string]string{http.HeaderContentType: "text/csv; charset=utf-8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var items []string
+	if err = resp.ScanBody(&items); err != nil || len(items) != 2 || items[1] != "b" {
+		t.Errorf("ScanBody() csv = %v, err = %v", items, err)
+	}
+
+	resp, err = client.Get("/vnd", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data map[string]int
+	if err = resp.ScanBody(&data); err != nil || data["id"] != 1 {
+		t.Errorf("ScanBody() +json = %v, err = %v", data, err)
+	}
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+func TestClient_StatusError(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(stdhttp.StatusInternalServerError)
+		w.Write([]byte(`{"code":1001,"message":"internal error"}`))
+	}))
+	defer server.Close()
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+
+	if _, err := client.Get("/", nil); err != nil {
+		t.Fatalf("Get() without status error err = %v", err)
+	}
+
+	var apiErr apiError
+	_, err := client.Get("/users", nil, &http.RequestOptions{Error: &apiErr})
+
+	var statusErr *http.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Get() err = %v, want *StatusError", err)
+	}
+
+	if statusErr.StatusCode != stdhttp.StatusInternalServerError || statusErr.Method != http.MethodGet || !strings.HasSuffix(statusErr.URL, "/users") {
+		t.Errorf("Get() status error = %+v", statusErr)
+	}
+
+	if apiErr.Code != 1001 {
+		t.Errorf("Get() api error = %+v", apiErr)
+	}
+
+	client.SetError(apiError{})
+
+	_, err = client.Get("/", nil)
+
+	var target *apiError
+	if !errors.As(err, &target) || target.Message != "internal error" {
+		t.Errorf("Get() registered error = %v", err)
+	}
+
+	registered := apiError{Message: "stale"}
+	client.SetError(&registered)
+
+	if _, err = client.Get("/", nil); !errors.As(err, &target) || target != &registered {
+		t.Errorf("Get() registered pointer err = %v", err)
+	}
+
+	if registered.Code != 1001 || registered.Message != "internal error" {
+		t.Errorf("Get() registered pointer = %+v, want filled", registered)
+	}
+}
+
+func TestClient_Problem(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		if r.URL.Path == "/xml" {
+			w.Header().Set("Content-Type", http.ContentTypeProblemXml)
+			w.WriteHeader(stdhttp.StatusForbidden)
+			w.Write([]byte(`<problem xmlns="urn:ietf:rfc:7807"><type>https://example.com/probs/out-of-credit</type><title>You do not have enough credit.</title><balance>30</balance></problem>`))
+			return
+		}
+
+		w.Header().Set("Content-Type", http.ContentTypeProblemJson)
+		w.WriteHeader(stdhttp.StatusForbidden)
+		w.Write([]byte(`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"balance":30}`))
+	}))
+	defer server.Close()
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetStatusError(true)
+
+	for _, path := range []string{"/json", "/xml"} {
+		_, err := client.Get(path, nil)
+
+		var problem *http.Problem
+		if !errors.As(err, &problem) {
+			t.Fatalf("Get(%s) err = %v, want *Problem", path, err)
+		}
+
+		if problem.Type != "https://example.com/probs/out-of-credit" || problem.Status != stdhttp.StatusForbidden || problem.Extensions["balance"] == nil {
+			t.Errorf("Get(%s) problem = %+v", path, problem)
+		}
+
+		var statusErr *http.StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != stdhttp.StatusForbidden {
+			t.Errorf("Get(%s) err = %v, want wrapped *StatusError", path, err)
+		}
+	}
+
+	client.SetStatusError(false)
+
+	resp, err := client.Get("/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if problem := resp.Problem(); problem == nil || problem.Title != "You do not have enough credit." {
+		t.Errorf("Problem() without status error = %+v", problem)
+	}
+}
+
+func TestProblem_MarshalXML(t *testing.T) {
+	problem := http.Problem{
+		Type:   "https://example.com/probs/invalid",
+		Status: stdhttp.StatusBadRequest,
+		Extensions: map[string]interface{}{
+			"errors":  []interface{}{map[string]interface{}{"field": "age", "reason": "negative"}},
+			"balance": 30,
+			"ignored": nil,
+		},
+	}
+
+	buf, err := xml.Marshal(problem)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<problem xmlns="urn:ietf:rfc:7807"><type>https://example.com/probs/invalid</type><status>400</status>` +
+		`<balance>30</balance><errors><i><field>age</field><reason>negative</reason></i></errors></problem>`
+	if string(buf) != want {
+		t.Errorf("MarshalXML() = %s, want %s", buf, want)
+	}
+
+	var decoded http.Problem
+	if err = xml.Unmarshal(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, _ := decoded.Extensions["errors"].([]interface{})
+	if len(errs) != 1 || errs[0].(map[string]interface{})["reason"] != "negative" || decoded.Extensions["balance"] != "30" {
+		t.Errorf("UnmarshalXML() extensions = %v", decoded.Extensions)
+	}
+}
+
+func TestGetAs(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		if r.URL.Query().Get("id") != "1" {
+			w.WriteHeader(stdhttp.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<user><id>1</id><name>fuxiao</name></user>`))
+	}))
+	defer server.Close()
+
+	type user struct {
+		ID   int    `xml:"id"`
+		Name string `xml:"name"`
+	}
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+
+	u, resp, err := http.GetAs[user](context.Background(), client, "/users", "id=1")
+	if err != nil || resp == nil || u.Name != "fuxiao" {
+		t.Errorf("GetAs() user = %+v, err = %v", u, err)
+	}
+
+	pu, _, err := http.GetAs[*user](context.Background(), client, "/users", "id=1")
+	if err != nil || pu == nil || pu.Name != "fuxiao" {
+		t.Errorf("GetAs() *user = %+v, err = %v", pu, err)
+	}
+
+	_, resp, err = http.GetAs[*user](context.Background(), client, "/users", "id=2")
+
+	var statusErr *http.StatusError
+	if !errors.As(err, &statusErr) || resp.StatusCode != stdhttp.StatusNotFound {
+		t.Errorf("GetAs() err = %v, want *StatusError", err)
+	}
+}
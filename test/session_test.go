@@ -0,0 +1,134 @@
+package test_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/dobyte/http"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_NewSession(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		switch r.URL.Path {
+		case "/login":
+			r.ParseForm()
+			stdhttp.SetCookie(w, &stdhttp.Cookie{Name: "sid", Value: r.PostForm.Get("user")})
+		case "/token":
+			w.Header().Set(http.HeaderContentType, http.ContentTypeJson)
+			w.Write([]byte(`{"data":{"access_token":"t0k3n"}}`))
+		default:
+			w.Write([]byte(r.Header.Get(http.HeaderCookie) + "|" + r.Header.Get(http.HeaderAuthorization) + "|" + r.Header.Get("X-Trace")))
+		}
+	}))
+	defer server.Close()
+
+	var calls int
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.Use(func(r http.Request) (*http.Response, error) {
+		calls++
+		r.Request().Header.Set("X-Trace", "1")
+		return r.Next()
+	})
+
+	alice, bob := client.NewSession(), client.NewSession()
+	bob.SetBearerToken("bob")
+
+	if _, err := alice.Login("/login", "user=alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bob.LoginBearer("/token", nil, "data.access_token"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		client interface {
+			Get(url string, data interface{}, opts ...*http.RequestOptions) (*http.Response, error)
+		}
+		want string
+	}{
+		{name: "alice", client: alice, want: "sid=alice||1"},
+		{name: "bob", client: bob, want: "|Bearer t0k3n|1"},
+		{name: "client", client: client, want: "||1"},
+	}
+
+	for _, tt := range tests {
+		resp, err := tt.client.Get("/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if body, _ := resp.ReadBody(); string(body) != tt.want {
+			t.Errorf("%s: Get() = %s, want %s", tt.name, body, tt.want)
+		}
+	}
+
+	if calls != 5 {
+		t.Errorf("middleware calls = %d, want 5", calls)
+	}
+
+	b, err := json.Marshal(alice.Export())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alice.Logout()
+
+	var state http.SessionState
+	if err = json.Unmarshal(b, &state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := client.NewSession()
+	if err = restored.Import(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]*http.Session{"logout": alice, "restored": restored} {
+		want := map[string]string{"logout": "||1", "restored": "sid=alice||1"}[name]
+
+		resp, err := s.Get("/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if body, _ := resp.ReadBody(); string(body) != want {
+			t.Errorf("%s: Get() = %s, want %s", name, body, want)
+		}
+	}
+}
+
+func TestSession_SetError(t *testing.T) {
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		w.Header().Set(http.HeaderContentType, http.ContentTypeJson)
+		w.WriteHeader(stdhttp.StatusBadRequest)
+		w.Write([]byte(`{"code":400,"message":"` + r.URL.Query().Get("user") + `"}`))
+	}))
+	defer server.Close()
+
+	var registered apiError
+
+	client := http.NewClient()
+	client.SetBaseUrl(server.URL)
+	client.SetError(&registered)
+
+	alice, bob := client.NewSession(), client.NewSession()
+
+	for name, s := range map[string]*http.Session{"alice": alice, "bob": bob} {
+		_, err := s.Get("/?user="+name, nil)
+
+		var target *apiError
+		if !errors.As(err, &target) || target == &registered || target.Message != name {
+			t.Errorf("%s: Get() err = %v", name, err)
+		}
+	}
+
+	if registered != (apiError{}) {
+		t.Errorf("sessions filled the error of the client: %+v", registered)
+	}
+}
@@ -0,0 +1,61 @@
+package http
+
+import "context"
+
+// RequestAs Send a http request and decode the body of a 2xx response into T.
+// A non-2xx response is returned as a *StatusError, and the response is returned as long as it was received.
+func RequestAs[T any](ctx context.Context, c *Client, method, url string, data interface{}, opts ...*RequestOptions) (T, *Response, error) {
+	var (
+		result T
+		opt    RequestOptions
+	)
+
+	if len(opts) > 0 && opts[0] != nil {
+		opt = *opts[0]
+	}
+
+	opt.Context, opt.StatusError = ctx, true
+
+	resp, err := c.Request(method, url, data, &opt)
+	if err != nil {
+		return result, resp, err
+	}
+
+	buf, err := resp.ReadBody()
+	if err != nil {
+		return result, resp, err
+	}
+
+	if len(buf) > 0 {
+		if err = resp.ScanBody(&result); err != nil {
+			return result, resp, err
+		}
+	}
+
+	return result, resp, nil
+}
+
+// GetAs Send a http request use get method and decode the response into T.
+func GetAs[T any](ctx context.Context, c *Client, url string, query interface{}, opts ...*RequestOptions) (T, *Response, error) {
+	return RequestAs[T](ctx, c, MethodGet, url, query, opts...)
+}
+
+// PostAs Send a http request use post method and decode the response into T.
+func PostAs[T any](ctx context.Context, c *Client, url string, body interface{}, opts ...*RequestOptions) (T, *Response, error) {
+	return RequestAs[T](ctx, c, MethodPost, url, body, opts...)
+}
+
+// PutAs Send a http request use put method and decode the response into T.
+func PutAs[T any](ctx context.Context, c *Client, url string, body interface{}, opts ...*RequestOptions) (T, *Response, error) {
+	return RequestAs[T](ctx, c, MethodPut, url, body, opts...)
+}
+
+// PatchAs Send a http request use patch method and decode the response into T.
+func PatchAs[T any](ctx context.Context, c *Client, url string, body interface{}, opts ...*RequestOptions) (T, *Response, error) {
+	return RequestAs[T](ctx, c, MethodPatch, url, body, opts...)
+}
+
+// DeleteAs Send a http request use delete method and decode the response into T.
+func DeleteAs[T any](ctx context.Context, c *Client, url string, data interface{}, opts ...*RequestOptions) (T, *Response, error) {
+	return RequestAs[T](ctx, c, MethodDelete, url, data, opts...)
+}
@@ -25,9 +25,23 @@ const (
 type FieldType = multipart.FieldType
 
 type UploadOptions struct {
-	Headers   map[string]string
+	// Headers replace the common headers with the same canonical key.
+	Headers map[string]string
+	// Header is multi-valued headers, which replace the common headers and Headers with the same canonical key.
+	Header http.Header
+	// Cookies are sent with the request, replacing the common cookies with the same name.
 	Cookies   map[string]string
 	FieldType FieldType
+	// StatusError returns a non-2xx response as a *StatusError.
+	StatusError bool
+	// Error is filled with the body of a non-2xx response, and enables StatusError.
+	Error interface{}
+	// Context overrides the context of the client for the request.
+	Context context.Context
+	// Query is a struct, a map or url.Values merged into the query of the url.
+	Query interface{}
+	// PathParams is a map or a struct with path tags, eg: `path:"org"`.
+	PathParams interface{}
 }
 
 type upload struct {
@@ -51,19 +65,46 @@ func (r *upload) request(url string, files, data interface{}, opts ...*UploadOpt
 // build a http request.
 func (r *upload) prepare(url string, files, data interface{}, opts ...*UploadOptions) (req *http.Request, err error) {
 	var (
-		buffer  = &bytes.Buffer{}
-		writer  = multipart.NewWriter(buffer)
-		headers = r.client.GetHeaders()
-		cookies = r.client.GetCookies()
+		buffer   = &bytes.Buffer{}
+		writer   = multipart.NewWriter(buffer)
+		headers  = r.client.cloneHeaders()
+		cookies  = make(map[string]string)
+		ctx      = r.client.ctx
+		params   map[string]string
+		template = url
 	)
 
-	if err = r.writeFiles(writer, files); err != nil {
+	writer.SetCodecs(r.client.codecs)
+
+	set, err := r.writeFiles(writer, files)
+	if err != nil {
 		return
 	}
 
 	fieldType := FieldTypeNone
-	if len(opts) > 0 {
+	if len(opts) > 0 && opts[0] != nil {
 		fieldType = opts[0].FieldType
+		r.statusError, r.errorResult = opts[0].StatusError, opts[0].Error
+
+		if params, err = parsePathParams(opts[0].PathParams); err != nil {
+			return
+		}
+
+		for key, value := range opts[0].Headers {
+			headers.Set(key, value)
+		}
+
+		for key, values := range opts[0].Header {
+			headers[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+		}
+
+		for key, value := range opts[0].Cookies {
+			cookies[key] = value
+		}
+
+		if opts[0].Context != nil {
+			ctx = opts[0].Context
+		}
 	}
 
 	if err = r.writeData(writer, data, fieldType); err != nil {
@@ -72,32 +113,42 @@ func (r *upload) prepare(url string, files, data interface{}, opts ...*UploadOpt
 
 	_ = writer.Close()
 
-	req, err = http.NewRequest(MethodPost, r.makeUrl(url), buffer)
-	if err != nil {
+	if url, err = expandTemplate(url, params); err != nil {
 		return
 	}
 
-	if r.client.ctx != nil {
-		req = req.WithContext(r.client.ctx)
-	} else {
-		req = req.WithContext(context.Background())
+	if url, err = r.makeUrl(url); err != nil {
+		return
 	}
 
-	for key, value := range headers {
-		switch key {
-		case HeaderContentType, HeaderCookie:
-			// ignore
-		default:
-			req.Header.Set(key, value)
+	if len(opts) > 0 && opts[0] != nil && opts[0].Query != nil {
+		var values map[string][]string
+		if values, err = r.client.queryEncoder.Values(opts[0].Query); err != nil {
+			return
 		}
-	}
 
-	if len(cookies) > 0 {
-		slice := make([]string, 0, len(cookies))
-		for key, value := range r.client.cookies {
-			slice = append(slice, key+"="+value)
+		if url, err = mergeQuery(url, values, true); err != nil {
+			return
 		}
-		req.Header.Set(HeaderCookie, strings.Join(slice, ";"))
+	}
+
+	req, err = http.NewRequest(MethodPost, url, buffer)
+	if err != nil {
+		return
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx = context.WithValue(ctx, uploadFilesKey, set)
+	req = req.WithContext(context.WithValue(ctx, templateKey, template))
+
+	headers.Del(HeaderCookie)
+	req.Header = headers
+
+	if cookie := cookieHeader(r.client.cookies.match(req.URL), cookies); cookie != "" {
+		req.Header.Set(HeaderCookie, cookie)
 	}
 
 	req.Host = req.Header.Get(HeaderHost)
@@ -106,7 +157,7 @@ func (r *upload) prepare(url string, files, data interface{}, opts ...*UploadOpt
 	return
 }
 
-func (r *upload) writeFiles(writer *multipart.Writer, files interface{}) error {
+func (r *upload) writeFiles(writer *multipart.Writer, files interface{}) (fileset, error) {
 	set := make(fileset)
 	switch v := files.(type) {
 	case map[string]string:
@@ -182,7 +233,7 @@ func (r *upload) writeFiles(writer *multipart.Writer, files interface{}) error {
 				}
 			}
 		default:
-			return errors.New("files type must be map or struct")
+			return nil, errors.New("files type must be map or struct")
 		}
 	}
 
@@ -195,28 +246,28 @@ func (r *upload) writeFiles(writer *multipart.Writer, files interface{}) error {
 	for name, paths := range set {
 		for _, path := range paths {
 			if !xfile.Exists(path) {
-				return errors.New(fmt.Sprintf(`"%s" does not exist`, path))
+				return nil, errors.New(fmt.Sprintf(`"%s" does not exist`, path))
 			}
 
 			stream, err = writer.CreateFormFile(name, filepath.Base(path))
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			file, err = os.Open(path)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			_, err = io.Copy(stream, file)
 			_ = file.Close()
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
 
-	return nil
+	return set, nil
 }
 
 func (r *upload) writeData(writer *multipart.Writer, data interface{}, fieldType FieldType) (err error) {
@@ -230,6 +281,9 @@ func (r *upload) writeData(writer *multipart.Writer, data interface{}, fieldType
 	)
 
 	for kind == reflect.Ptr {
+		if rv.IsNil() {
+			return
+		}
 		rv = rv.Elem()
 		kind = rv.Kind()
 	}
@@ -255,6 +309,9 @@ func (r *upload) writeData(writer *multipart.Writer, data interface{}, fieldType
 					break
 				}
 			}
+			if name = strings.Split(name, ",")[0]; name == "-" {
+				continue
+			}
 			if name == "" {
 				name = rt.Field(i).Name
 			}
@@ -270,6 +327,9 @@ func (r *upload) writeData(writer *multipart.Writer, data interface{}, fieldType
 	return
 }
 
+const uploadFilesKey = "__httpClientUploadFilesKey"
+
+// fileset is the paths of the uploaded files by their field names.
 type fileset map[string][]string
 
 func (fs fileset) add(name string, path string) {
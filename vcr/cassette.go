@@ -0,0 +1,160 @@
+package vcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const cassetteVersion = 1
+
+// Cassette is the interactions recorded in a file, the format is json for a .json file and yaml otherwise.
+type Cassette struct {
+	Version      int            `json:"version" yaml:"version"`
+	Interactions []*Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// Interaction is a request and its response.
+type Interaction struct {
+	Request  Request  `json:"request" yaml:"request"`
+	Response Response `json:"response" yaml:"response"`
+}
+
+type Request struct {
+	Method  string      `json:"method" yaml:"method"`
+	URL     string      `json:"url" yaml:"url"`
+	Headers http.Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string      `json:"body,omitempty" yaml:"body,omitempty"`
+	// Encoding is base64 for a binary body.
+	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+}
+
+type Response struct {
+	Status  int         `json:"status" yaml:"status"`
+	Headers http.Header `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string      `json:"body,omitempty" yaml:"body,omitempty"`
+	// Encoding is base64 for a binary body.
+	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+}
+
+// Bytes returns the decoded body.
+func (r *Request) Bytes() []byte {
+	return decodeBody(r.Body, r.Encoding)
+}
+
+// SetBody set the body, which is base64 encoded unless it's valid utf-8.
+func (r *Request) SetBody(body []byte) {
+	r.Body, r.Encoding = encodeBody(body)
+}
+
+// Bytes returns the decoded body.
+func (r *Response) Bytes() []byte {
+	return decodeBody(r.Body, r.Encoding)
+}
+
+// SetBody set the body, which is base64 encoded unless it's valid utf-8.
+func (r *Response) SetBody(body []byte) {
+	r.Body, r.Encoding = encodeBody(body)
+}
+
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeBody(body, encoding string) []byte {
+	if encoding == "base64" {
+		if buf, err := base64.StdEncoding.DecodeString(body); err == nil {
+			return buf
+		}
+	}
+
+	return []byte(body)
+}
+
+// Load Read a cassette file.
+func Load(filename string) (*Cassette, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cassette{}
+	if isJson(filename) {
+		err = json.Unmarshal(data, c)
+	} else {
+		err = yaml.Unmarshal(data, c)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("vcr: parse cassette %s: %w", filename, err)
+	}
+
+	return c, nil
+}
+
+// Save Write the cassette to a file, which is replaced atomically.
+func (c *Cassette) Save(filename string) error {
+	var (
+		data []byte
+		err  error
+	)
+
+	c.Version = cassetteVersion
+
+	if isJson(filename) {
+		data, err = json.MarshalIndent(c, "", "  ")
+	} else {
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err = encoder.Encode(c); err == nil {
+			err = encoder.Close()
+		}
+		data = buf.Bytes()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err == nil {
+		err = f.Chmod(0644)
+	}
+
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), filename)
+}
+
+func isJson(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".json")
+}
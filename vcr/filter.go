@@ -0,0 +1,150 @@
+package vcr
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Filtered replaces the filtered values.
+const Filtered = "[FILTERED]"
+
+var defaultFilterHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+type filter struct {
+	headers []string
+	query   map[string]bool
+	fields  map[string]bool
+	fn      func(i *Interaction)
+}
+
+func newFilter(opts *Options) *filter {
+	f := &filter{query: make(map[string]bool), fields: make(map[string]bool), fn: opts.Filter}
+
+	for _, key := range append(defaultFilterHeaders, opts.FilterHeaders...) {
+		f.headers = append(f.headers, http.CanonicalHeaderKey(key))
+	}
+
+	for _, key := range opts.FilterQuery {
+		f.query[key] = true
+	}
+
+	for _, field := range opts.FilterFields {
+		f.fields[strings.ToLower(field)] = true
+	}
+
+	return f
+}
+
+// apply filter the request of the interaction, and its response when response is true.
+func (f *filter) apply(i *Interaction, response bool) {
+	f.filterHeader(i.Request.Headers)
+	i.Request.URL = f.filterUrl(i.Request.URL)
+
+	if len(f.fields) > 0 {
+		i.Request.SetBody(f.filterBody(i.Request.Bytes(), i.Request.Headers.Get("Content-Type")))
+	}
+
+	if response {
+		f.filterHeader(i.Response.Headers)
+
+		if len(f.fields) > 0 {
+			i.Response.SetBody(f.filterBody(i.Response.Bytes(), i.Response.Headers.Get("Content-Type")))
+		}
+	}
+
+	if f.fn != nil {
+		f.fn(i)
+	}
+}
+
+func (f *filter) filterHeader(header http.Header) {
+	for _, key := range f.headers {
+		if values, ok := header[key]; ok {
+			for i := range values {
+				values[i] = Filtered
+			}
+		}
+	}
+}
+
+func (f *filter) filterUrl(rawUrl string) string {
+	if len(f.query) == 0 {
+		return rawUrl
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+
+	query, filtered := u.Query(), false
+	for key, values := range query {
+		if f.query[key] {
+			for i := range values {
+				values[i] = Filtered
+			}
+			filtered = true
+		}
+	}
+
+	if !filtered {
+		return rawUrl
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func (f *filter) filterBody(body []byte, contentType string) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+
+		for key := range values {
+			if f.fields[strings.ToLower(key)] {
+				values[key] = []string{Filtered}
+			}
+		}
+
+		return []byte(values.Encode())
+	case json.Valid(body):
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return body
+		}
+
+		if buf, err := json.Marshal(f.filterJson(v)); err == nil {
+			return buf
+		}
+	}
+
+	return body
+}
+
+func (f *filter) filterJson(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if f.fields[strings.ToLower(key)] {
+				val[key] = Filtered
+			} else {
+				val[key] = f.filterJson(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = f.filterJson(item)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,88 @@
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Matcher reports whether a request matches a recorded one, the request is filtered as the recorded ones were.
+type Matcher func(r *Request, recorded *Request) bool
+
+// DefaultMatchers match the method and the url.
+var DefaultMatchers = []Matcher{MatchMethod, MatchURL}
+
+// MatchMethod matches the methods.
+func MatchMethod(r *Request, recorded *Request) bool {
+	return r.Method == recorded.Method
+}
+
+// MatchURL matches the urls, including the query in its order.
+func MatchURL(r *Request, recorded *Request) bool {
+	return r.URL == recorded.URL
+}
+
+// MatchPath matches the urls without their queries.
+func MatchPath(r *Request, recorded *Request) bool {
+	u1, err1 := url.Parse(r.URL)
+	u2, err2 := url.Parse(recorded.URL)
+	if err1 != nil || err2 != nil {
+		return r.URL == recorded.URL
+	}
+
+	u1.RawQuery, u2.RawQuery, u1.Fragment, u2.Fragment = "", "", "", ""
+
+	return u1.String() == u2.String()
+}
+
+// MatchQuery matches the query parameters in any order.
+func MatchQuery(r *Request, recorded *Request) bool {
+	u1, err1 := url.Parse(r.URL)
+	u2, err2 := url.Parse(recorded.URL)
+	if err1 != nil || err2 != nil {
+		return r.URL == recorded.URL
+	}
+
+	return reflect.DeepEqual(u1.Query(), u2.Query())
+}
+
+// MatchBody matches the bodies, json bodies are compared by value and form bodies in any order.
+func MatchBody(r *Request, recorded *Request) bool {
+	b1, b2 := r.Bytes(), recorded.Bytes()
+	if bytes.Equal(b1, b2) {
+		return true
+	}
+
+	if json.Valid(b1) && json.Valid(b2) {
+		var v1, v2 interface{}
+		_ = json.Unmarshal(b1, &v1)
+		_ = json.Unmarshal(b2, &v2)
+		return reflect.DeepEqual(v1, v2)
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Headers.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		v1, err1 := url.ParseQuery(string(b1))
+		v2, err2 := url.ParseQuery(string(b2))
+		return err1 == nil && err2 == nil && reflect.DeepEqual(v1, v2)
+	}
+
+	return false
+}
+
+// MatchHeaders returns a matcher of the values of the headers.
+func MatchHeaders(keys ...string) Matcher {
+	return func(r *Request, recorded *Request) bool {
+		for _, key := range keys {
+			key = http.CanonicalHeaderKey(key)
+			if v1, v2 := r.Headers[key], recorded.Headers[key]; len(v1) != len(v2) || strings.Join(v1, "\n") != strings.Join(v2, "\n") {
+				return false
+			}
+		}
+
+		return true
+	}
+}
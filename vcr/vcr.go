@@ -0,0 +1,318 @@
+// Package vcr records the interactions of a http client in cassette files, and replays them in tests.
+//
+//	client := http.NewClient()
+//	client.Transport = vcr.NewTest(t, "testdata/users.yaml", &vcr.Options{Transport: client.Transport})
+package vcr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeAuto replays the cassette if it exists, otherwise records it.
+	ModeAuto Mode = iota
+	// ModeReplay replays the cassette, which must exist.
+	ModeReplay
+	// ModeRecord sends every request and records the cassette again.
+	ModeRecord
+)
+
+// ErrNoInteraction is returned in strict mode for a request which matches no interaction of the cassette.
+var ErrNoInteraction = errors.New("vcr: no interaction matches the request")
+
+type Options struct {
+	// Mode defaults to ModeAuto.
+	Mode Mode
+	// Transport sends the requests which are recorded or not matched, defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Matchers match the requests against the recorded ones, defaults to DefaultMatchers.
+	Matchers []Matcher
+	// Strict fails the requests matching no interaction in replay, instead of sending them by the Transport.
+	// Each interaction is replayed once in strict mode, and repeatedly otherwise.
+	Strict bool
+	// FilterHeaders are filtered along with Authorization, Proxy-Authorization, Cookie and Set-Cookie.
+	FilterHeaders []string
+	// FilterQuery are the query parameters filtered.
+	FilterQuery []string
+	// FilterFields are the json members and form fields filtered in the request and response bodies,
+	// matched case-insensitively, eg: password.
+	FilterFields []string
+	// Filter is called with each interaction after the other filters, before it's saved or matched.
+	// Only the request of the interaction is set when it's called for matching.
+	Filter func(i *Interaction)
+}
+
+// Recorder is a http.RoundTripper recording or replaying a cassette.
+type Recorder struct {
+	filename string
+	opts     Options
+	mode     Mode
+	filter   *filter
+	t        testing.TB
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+	changed  bool
+}
+
+// New Create a recorder of the cassette file.
+func New(filename string, opts ...*Options) (*Recorder, error) {
+	r := &Recorder{filename: filename, cassette: &Cassette{Version: cassetteVersion}}
+	if len(opts) > 0 && opts[0] != nil {
+		r.opts = *opts[0]
+	}
+
+	if r.opts.Transport == nil {
+		r.opts.Transport = http.DefaultTransport
+	}
+
+	if len(r.opts.Matchers) == 0 {
+		r.opts.Matchers = DefaultMatchers
+	}
+
+	r.filter = newFilter(&r.opts)
+
+	r.mode = r.opts.Mode
+	if r.mode == ModeAuto {
+		if _, err := os.Stat(filename); err == nil {
+			r.mode = ModeReplay
+		} else if os.IsNotExist(err) {
+			r.mode = ModeRecord
+		} else {
+			return nil, err
+		}
+	}
+
+	if r.mode == ModeReplay {
+		cassette, err := Load(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		r.cassette, r.used = cassette, make([]bool, len(cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// NewTest Create a recorder of the cassette file for a test, which is stopped when the test finishes.
+// The test fails if the recorder can't be created or saved, or in strict mode, on a request matching no interaction.
+func NewTest(t testing.TB, filename string, opts ...*Options) *Recorder {
+	t.Helper()
+
+	r, err := New(filename, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.t = t
+
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	return r
+}
+
+// Mode returns the mode of the recorder, ModeAuto is resolved to ModeReplay or ModeRecord.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Cassette returns the cassette replayed or being recorded.
+func (r *Recorder) Cassette() *Cassette {
+	return r.cassette
+}
+
+// Stop Save the cassette when it's recorded.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mode != ModeRecord || !r.changed {
+		return nil
+	}
+
+	r.changed = false
+
+	return r.cassette.Save(r.filename)
+}
+
+// RoundTrip Replay or record the request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeRecord {
+		return r.record(req, body)
+	}
+
+	i := &Interaction{Request: newRequest(req, body)}
+	r.filter.apply(i, false)
+
+	if resp := r.replay(req, &i.Request); resp != nil {
+		return resp, nil
+	}
+
+	if r.opts.Strict {
+		err = fmt.Errorf("%w: %s %s in %s", ErrNoInteraction, req.Method, i.Request.URL, r.filename)
+		if r.t != nil {
+			r.t.Error(err)
+		}
+		return nil, err
+	}
+
+	return r.opts.Transport.RoundTrip(req)
+}
+
+// replay returns the response of the first interaction matching the request, preferring the ones not replayed yet.
+func (r *Recorder) replay(req *http.Request, recorded *Request) *http.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for index, i := range r.cassette.Interactions {
+		if !r.match(recorded, &i.Request) {
+			continue
+		}
+
+		if !r.used[index] {
+			match = index
+			break
+		}
+
+		if match < 0 && !r.opts.Strict {
+			match = index
+		}
+	}
+
+	if match < 0 {
+		return nil
+	}
+
+	r.used[match] = true
+
+	return newResponse(req, &r.cassette.Interactions[match].Response)
+}
+
+func (r *Recorder) match(req *Request, recorded *Request) bool {
+	for _, matcher := range r.opts.Matchers {
+		if !matcher(req, recorded) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// record send the request and record its response.
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.opts.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(buf))
+
+	i := &Interaction{
+		Request:  newRequest(req, body),
+		Response: Response{Status: resp.StatusCode, Headers: resp.Header.Clone()},
+	}
+	i.Response.SetBody(buf)
+	r.filter.apply(i, true)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.changed = true
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// readBody read the body of the request, which is restored for sending.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		return io.ReadAll(rc)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func newRequest(req *http.Request, body []byte) Request {
+	r := Request{Method: req.Method, URL: req.URL.String(), Headers: req.Header.Clone()}
+	if req.Host != "" && req.Host != req.URL.Host {
+		if r.Headers == nil {
+			r.Headers = make(http.Header)
+		}
+		r.Headers.Set("Host", req.Host)
+	}
+	r.SetBody(body)
+
+	return r
+}
+
+func newResponse(req *http.Request, recorded *Response) *http.Response {
+	body := recorded.Bytes()
+
+	resp := &http.Response{
+		Status:        strconv.Itoa(recorded.Status) + " " + http.StatusText(recorded.Status),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+
+	if resp.Header.Get("Content-Length") != "" {
+		resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	return resp
+}
@@ -0,0 +1,140 @@
+package vcr_test
+
+import (
+	"errors"
+	"github.com/dobyte/http"
+	"github.com/dobyte/http/vcr"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newServer(hits *int32) *httptest.Server {
+	return httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		atomic.AddInt32(hits, 1)
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			body = []byte("null")
+		}
+
+		w.Header().Set(http.HeaderContentType, http.ContentTypeJson)
+		stdhttp.SetCookie(w, &stdhttp.Cookie{Name: "session", Value: "secret-session"})
+		w.Write([]byte(`{"method":"` + r.Method + `","token":"secret-token","echo":` + string(body) + `}`))
+	}))
+}
+
+func TestRecorder(t *testing.T) {
+	for _, name := range []string{"users.yaml", "users.json"} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				hits     int32
+				server   = newServer(&hits)
+				filename = filepath.Join(t.TempDir(), "cassettes", name)
+				opts     = &vcr.Options{
+					FilterQuery:  []string{"api_key"},
+					FilterFields: []string{"password", "token"},
+					Matchers:     append(vcr.DefaultMatchers, vcr.MatchBody),
+				}
+			)
+
+			send := func(recorder *vcr.Recorder) []string {
+				client := http.NewClient()
+				client.Transport = recorder
+				client.SetBearerToken("my-bearer")
+
+				var bodies []string
+				for _, password := range []string{"1", "2"} {
+					resp, err := client.Post(server.URL+"/login?api_key=k1", `{"user":"a","password":"`+password+`"}`)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					body, _ := resp.ReadBody()
+					bodies = append(bodies, string(body))
+				}
+
+				return bodies
+			}
+
+			recorder, err := vcr.New(filename, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if recorder.Mode() != vcr.ModeRecord {
+				t.Fatalf("Mode() = %d, want ModeRecord", recorder.Mode())
+			}
+
+			recorded := send(recorder)
+			if err = recorder.Stop(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, _ := os.ReadFile(filename)
+			for _, secret := range []string{"my-bearer", "k1", "secret-token", "secret-session"} {
+				if strings.Contains(string(data), secret) {
+					t.Errorf("cassette contains %q:\n%s", secret, data)
+				}
+			}
+
+			server.Close()
+
+			if recorder, err = vcr.New(filename, &vcr.Options{Strict: true, Matchers: opts.Matchers, FilterQuery: opts.FilterQuery, FilterFields: opts.FilterFields}); err != nil {
+				t.Fatal(err)
+			}
+
+			if recorder.Mode() != vcr.ModeReplay {
+				t.Fatalf("Mode() = %d, want ModeReplay", recorder.Mode())
+			}
+
+			replayed := send(recorder)
+			if hits != 2 || len(replayed) != 2 || replayed[0] != `{"echo":{"password":"[FILTERED]","user":"a"},"method":"POST","token":"[FILTERED]"}` {
+				t.Errorf("replayed %v after %d hits, recorded %v", replayed, hits, recorded)
+			}
+
+			client := http.NewClient()
+			client.Transport = recorder
+
+			if _, err = client.Post(server.URL+"/login?api_key=k2", `{"user":"a","password":"1"}`); !errors.Is(err, vcr.ErrNoInteraction) {
+				t.Errorf("Post() of a replayed interaction = %v, want ErrNoInteraction", err)
+			}
+		})
+	}
+}
+
+func TestNewTest(t *testing.T) {
+	var (
+		hits     int32
+		server   = newServer(&hits)
+		filename = filepath.Join(t.TempDir(), "get.yaml")
+	)
+	defer server.Close()
+
+	t.Run("record", func(t *testing.T) {
+		client := http.NewClient()
+		client.Transport = vcr.NewTest(t, filename, &vcr.Options{Transport: client.Transport})
+
+		if _, err := client.Get(server.URL+"/users", nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		client := http.NewClient()
+		client.Transport = vcr.NewTest(t, filename, &vcr.Options{Matchers: []vcr.Matcher{vcr.MatchMethod, vcr.MatchPath}})
+
+		resp, err := client.Get(server.URL+"/users?page=2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if body, _ := resp.ReadBody(); !strings.Contains(string(body), `"method":"GET"`) || hits != 1 {
+			t.Errorf("Get() = %s after %d hits", body, hits)
+		}
+	})
+}